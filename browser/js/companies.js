@@ -79,47 +79,40 @@
 		ko.applyBindings({companies: company_list}, document.getElementById("company-list"));
 	});
 
-	function refresh(first_time) {
-		common.request('/game/companies', function (err, result) {
-			if (err) {
-				console.error('failed to get initial company info', err);
-				return;
-			}
-
-			company_list().forEach(function (company) {
-				var already_built = company.built_track();
-				result[company.name].built_track.forEach(function (id) {
-					if (already_built.indexOf(id) < 0) {
-						hex_map.build_rail(id, company.color);
-					}
-				});
-				ko.mapping.fromJS(result[company.name], company);
-				delete result[company.name];
+	// render updates the companies from the company info the server pushes along with every
+	// event. The first company is selected if none are yet.
+	function render(result) {
+		company_list().forEach(function (company) {
+			var already_built = company.built_track();
+			result[company.name].built_track.forEach(function (id) {
+				if (already_built.indexOf(id) < 0) {
+					hex_map.build_rail(id, company.color);
+				}
 			});
-			Object.keys(result).forEach(function (name) {
-				var view_model = ko.mapping.fromJS(result[name]);
-
-				view_model.name = name;
-				view_model.icon = common.get_company_logo(name);
-				view_model.color = common.get_company_color(name);
-				view_model.selected = ko.observable(false);
-				view_model.select = select_company.bind(null, name);
-				view_model.equipment_list = ko.computed(convert_equipment, view_model);
-				view_model.built_track().forEach(function (id) {
-					hex_map.build_rail(id, view_model.color);
-				});
-
-				company_list.push(view_model);
+			ko.mapping.fromJS(result[company.name], company);
+			delete result[company.name];
+		});
+		Object.keys(result).forEach(function (name) {
+			var view_model = ko.mapping.fromJS(result[name]);
+
+			view_model.name = name;
+			view_model.icon = common.get_company_logo(name);
+			view_model.color = common.get_company_color(name);
+			view_model.selected = ko.observable(false);
+			view_model.select = select_company.bind(null, name);
+			view_model.equipment_list = ko.computed(convert_equipment, view_model);
+			view_model.built_track().forEach(function (id) {
+				hex_map.build_rail(id, view_model.color);
 			});
-			sort_companies();
-			if (first_time) {
-				company_list()[0].selected(true);
-			}
+
+			company_list.push(view_model);
 		});
+		sort_companies();
+		if (!get_selected() && company_list().length > 0) {
+			company_list()[0].selected(true);
+		}
 	}
-	refresh(true);
-
-	module.exports.refresh   = refresh;
+	module.exports.render    = render;
 	module.exports.select    = select_company;
 	module.exports.selected  = get_selected;
 	module.exports.president = get_president;
@@ -18,6 +18,9 @@
 		all_costs:     ko.observableArray([]),
 
 		orphan_stocks: ko.observableArray([]),
+
+		finished:  ko.observable(false),
+		standings: ko.observableArray([]),
 	};
 	game_state.train_costs = ko.computed(function () {
 		var tech_level = this.tech_level();
@@ -57,48 +60,39 @@
 		}));
 	});
 
-	function log_error(err) {
-		if (err) {
-			console.error('failed to get game state', err);
-		}
-	}
-
-	function refresh(cb) {
-		if (typeof cb !== 'function') {
-			cb = log_error;
-		}
-
-		common.request('/game/state', function (err, repsonse) {
-			if (err) {
-				return cb(err);
-			}
-
-			game_state.round(repsonse.round);
-			game_state.phase(repsonse.phase);
-			game_state.turn(repsonse.turn);
-			game_state.stage(repsonse.stage);
+	// render updates the game state from the state view the server pushes along with every event.
+	function render(state) {
+		game_state.round(state.round);
+		game_state.phase(state.phase);
+		game_state.turn(state.turn);
+		game_state.stage(state.stage);
 
-			game_state.tech_level(repsonse.tech_level);
-			game_state.trains_bought(repsonse.trains_bought);
+		game_state.tech_level(state.tech_level);
+		game_state.trains_bought(state.trains_bought);
 
-			game_state.orphan_stocks(Object.keys(repsonse.orphan_stocks).map(function (name) {
-				return {
-					name:  name,
-					color: common.get_company_color(name),
-					count: repsonse.orphan_stocks[name],
-				};
-			}));
+		var orphan_stocks = state.orphan_stocks || {};
+		game_state.orphan_stocks(Object.keys(orphan_stocks).map(function (name) {
+			return {
+				name:  name,
+				color: common.get_company_color(name),
+				count: orphan_stocks[name],
+			};
+		}));
 
-			hex_map.set_coal(repsonse.unmined_coal);
+		hex_map.set_coal(state.unmined_coal || []);
 
-			cb();
-		});
+		game_state.standings(state.standings || []);
+		game_state.finished(state.finished);
 	}
 
-	module.exports.refresh = refresh;
+	module.exports.render = render;
 	module.exports.turn = ko.computed(function () {
 		return this.turn();
 	}, game_state);
+	module.exports.finished = ko.computed(function () {
+		return this.finished();
+	}, game_state);
+	module.exports.standings = game_state.standings;
 	module.exports.market_phase = ko.computed(function () {
 		return market_regexp.test(this.phase());
 	}, game_state);
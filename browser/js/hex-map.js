@@ -391,7 +391,7 @@
 		resize_canvas();
 	}
 
-	common.request('/board_info', function (err, board_info) {
+	common.request('/game/board', function (err, board_info) {
 		if (err) {
 			console.error('failed to get map state', err);
 			return;
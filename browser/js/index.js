@@ -41,6 +41,16 @@
 	};
 
 	function update_options() {
+		if (state.finished()) {
+			var winners = state.standings().filter(function (standing) {
+				return standing.rank === 1;
+			}).map(function (standing) {
+				return standing.player;
+			});
+			input_ctrl.description("Game Over: " + winners.join(" & ") + " won");
+			return;
+		}
+
 		var turn   = state.turn();
 		// make sure the state information has been initialized.
 		if (!turn) {
@@ -85,7 +95,22 @@
 	state.market_phase.subscribe(update_options);
 	state.business_part1.subscribe(update_options);
 	state.turn.subscribe(update_options);
-	state.refresh();
+	state.finished.subscribe(update_options);
+
+	// The server pushes an event every time anyone's turn is accepted, starting with a snapshot as
+	// soon as we connect. Every event carries the whole game, so we render it directly instead of
+	// polling or fetching anything after our own turns. The state goes last since
+	// changing the turn looks up the players and companies.
+	function render_event(event) {
+		var data = JSON.parse(event.data);
+		players.render(data.players);
+		companies.render(data.companies);
+		state.render(data.state);
+	}
+	var events = new EventSource('/game/events');
+	['snapshot', 'join', 'start', 'turn', 'phase', 'undo'].forEach(function (type) {
+		events.addEventListener(type, render_event);
+	});
 
 	function take_market_turn(market) {
 		var data = {};
@@ -114,9 +139,6 @@
 			if (errs) {
 				alert('Market Turn Failed', errs);
 			}
-			state.refresh();
-			players.refresh();
-			companies.refresh();
 		});
 	}
 
@@ -149,9 +171,6 @@
 			} else {
 				hex_map.deselect_hex('*');
 			}
-			state.refresh();
-			players.refresh();
-			companies.refresh();
 		});
 	}
 
@@ -172,9 +191,6 @@
 			} else {
 				hex_map.deselect_hex('*');
 			}
-			state.refresh();
-			players.refresh();
-			companies.refresh();
 		});
 	}
 
@@ -57,51 +57,44 @@
 	domready(function () {
 		ko.applyBindings({players: player_list}, document.getElementById("player-list"));
 	});
-	function refresh(select_first) {
-		common.request('/game/players', function (err, result) {
-			if (err) {
-				console.error('failed to get initial player info', err);
-				return;
-			}
-
-			player_list().forEach(function (player) {
-				var update = result[player.name];
-				ko.mapping.fromJS(update, player);
-				player.stock_list(Object.keys(update.stocks).map(function (name) {
-					return {
-						name:      name,
-						color:     common.get_company_color(name),
-						count:     update.stocks[name],
-					};
-				}));
-				delete result[player.name];
-			});
-			Object.keys(result).forEach(function (name) {
-				var view_model = ko.mapping.fromJS(result[name]);
-
-				view_model.name = name;
-				view_model.stock_list = ko.observableArray(Object.keys(view_model.stocks).map(function (name) {
-					return {
-						name:      name,
-						color:     common.get_company_color(name),
-						count:     view_model.stocks[name],
-					};
-				}));
-				view_model.selected = ko.observable(false);
-				view_model.select = select_player.bind(null, name);
+	// render updates the players from the player info the server pushes along with every event.
+	// The first player is selected if nobody is yet.
+	function render(result) {
+		player_list().forEach(function (player) {
+			var update = result[player.name];
+			ko.mapping.fromJS(update, player);
+			player.stock_list(Object.keys(update.stocks).map(function (name) {
+				return {
+					name:      name,
+					color:     common.get_company_color(name),
+					count:     update.stocks[name],
+				};
+			}));
+			delete result[player.name];
+		});
+		Object.keys(result).forEach(function (name) {
+			var view_model = ko.mapping.fromJS(result[name]);
 
-				player_list.push(view_model);
-			});
+			view_model.name = name;
+			view_model.stock_list = ko.observableArray(Object.keys(view_model.stocks).map(function (name) {
+				return {
+					name:      name,
+					color:     common.get_company_color(name),
+					count:     view_model.stocks[name],
+				};
+			}));
+			view_model.selected = ko.observable(false);
+			view_model.select = select_player.bind(null, name);
 
-			sort_players();
-			if (select_first) {
-				player_list()[0].selected(true);
-			}
+			player_list.push(view_model);
 		});
-	}
-	refresh(true);
 
-	module.exports.refresh     = refresh;
+		sort_players();
+		if (!get_selected() && player_list().length > 0) {
+			player_list()[0].selected(true);
+		}
+	}
+	module.exports.render      = render;
 	module.exports.select      = select_player;
 	module.exports.selected    = get_selected;
 	module.exports.held_shares = get_stocks;
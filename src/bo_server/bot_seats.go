@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"bots"
+)
+
+// addBot fills an open seat with a computer-controlled player using the named strategy. The seat's
+// token is thrown away, since nobody else should ever take the bot's turns. It must be called with
+// the lock held.
+func (r *gameRouter) addBot(playerName, strategy string) error {
+	if _, err := bots.New(strategy, 0); err != nil {
+		return err
+	}
+	token, err := r.join(playerName)
+	if err != nil {
+		return err
+	}
+	delete(r.tokens, token)
+	if r.bots == nil {
+		r.bots = make(map[string]string)
+	}
+	r.bots[playerName] = strategy
+	return nil
+}
+
+// botStrategy returns the strategy controlling the player, or nil if the player is a human. A new
+// strategy is created for every decision, seeded from the game's seed, the player's seat, and the
+// number of actions taken so far. That way a game restored from the store gives the bots the same
+// decisions they would have made otherwise.
+func (r *gameRouter) botStrategy(playerName string) (bots.Strategy, error) {
+	name := r.bots[playerName]
+	if name == "" {
+		return nil, nil
+	}
+
+	seed := r.game.Seed + int64(len(r.game.Log))
+	for ind, player := range r.players {
+		if player == playerName {
+			seed += int64(ind)
+		}
+	}
+	strategy, err := bots.New(name, seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot for %s: %v", playerName, err)
+	}
+	return strategy, nil
+}
+
+// playBots takes the turns for the bots until it's a human's turn or the game is over. Each turn
+// is saved and published the same as if a player had taken it. If a bot fails to take its turn
+// it is logged and the game waits, since trying again would only fail the same way. It must be
+// called with the lock held.
+func (r *gameRouter) playBots() {
+	for r.game != nil && !r.game.Finished {
+		actor, err := bots.Actor(r.game)
+		if err != nil {
+			log.Printf("failed to find the next player in game %q: %v", r.id, err)
+			return
+		}
+		strategy, err := r.botStrategy(actor)
+		if err != nil {
+			log.Printf("game %q: %v", r.id, err)
+			return
+		} else if strategy == nil {
+			return
+		}
+
+		if errs := bots.Play(strategy, r.game); len(errs) > 0 {
+			log.Printf("bot %s failed to take its turn in game %q: %v", actor, r.id, errs)
+			return
+		}
+		var resp jsonResponse
+		r.save(&resp)
+		r.publish(turnEvent)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"bots"
+	"gameState"
+)
+
+// TestBotSeats checks to make sure bots can fill open seats, and that they take their turns as
+// soon as it's their turn so the human only ever has to wait for other humans.
+func TestBotSeats(t *testing.T) {
+	handler := newLobbyRouter()
+	defer removeTestGame("bot_seats")
+
+	body := map[string]interface{}{"id": "bot_seats", "players": []string{"human"}, "seats": 3}
+	status, resp := doRequest(t, handler, "POST", "/games", body)
+	if status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+
+	join := map[string]string{"player_name": "robot", "bot": "not a strategy"}
+	if status, _ := doRequest(t, handler, "POST", "/games/bot_seats/join", join); status != 400 {
+		t.Errorf("joining with an unknown strategy returned status %d, expected 400", status)
+	}
+	for name, strategy := range map[string]string{"greedy": "greedy", "random": "random"} {
+		join := map[string]string{"player_name": name, "bot": strategy}
+		status, resp := doRequest(t, handler, "POST", "/games/bot_seats/join", join)
+		if status != 200 {
+			t.Fatalf("adding %s bot returned status %d: %+v", strategy, status, resp)
+		}
+	}
+
+	mapLock.RLock()
+	router := activeGames["bot_seats"]
+	mapLock.RUnlock()
+	if token := seatToken(router, "greedy"); token != "" {
+		t.Error("bot seat has a token")
+	}
+	token := seatToken(router, "human")
+
+	// Have the human take the simplest turn available until the game ends or runs long enough.
+	for ind := 0; ind < 20; ind += 1 {
+		router.lock.RLock()
+		finished := router.game.Finished
+		actor, err := bots.Actor(router.game)
+		phase, stage := router.game.Phase, router.game.Stage
+		router.lock.RUnlock()
+		if finished {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to find who acts next: %v", err)
+		} else if actor != "human" {
+			t.Fatalf("waiting for %s to act instead of the human", actor)
+		}
+
+		url, body := "/bot_seats/market_turn", interface{}(gameState.MarketTurn{})
+		if phase.Business() && stage == "inventory" {
+			url, body = "/bot_seats/business_turn_one", gameState.CompanyInventory{}
+		} else if phase.Business() {
+			url, body = "/bot_seats/business_turn_two", gameState.CompanyEarnings{}
+		}
+		status, resp := doSeatRequest(t, handler, token, "POST", url, body)
+		if status != 200 {
+			t.Fatalf("human's turn returned status %d: %+v", status, resp)
+		}
+	}
+
+	router.lock.RLock()
+	defer router.lock.RUnlock()
+	acted := make(map[string]bool)
+	for _, action := range router.game.Log {
+		acted[action.Player] = true
+	}
+	if !acted["greedy"] || !acted["random"] {
+		t.Errorf("bots didn't take any turns: %v", acted)
+	}
+}
+
+// TestBotStrategyRestore checks to make sure a bot makes the same decision in a game restored from
+// the store as it would have in the game that kept running.
+func TestBotStrategyRestore(t *testing.T) {
+	players := []string{"1st", "2nd", "3rd"}
+	running := &gameRouter{
+		id:      "bot_restore",
+		players: players,
+		game:    gameState.NewSeededGame(players, 5),
+		bots:    map[string]string{"1st": "random", "2nd": "random", "3rd": "random"},
+	}
+	for ind := 0; ind < 10 && !running.game.Finished; ind += 1 {
+		actor, err := bots.Actor(running.game)
+		if err != nil {
+			t.Fatalf("failed to find who acts next: %v", err)
+		}
+		strategy, err := running.botStrategy(actor)
+		if err != nil {
+			t.Fatalf("failed to create strategy for %s: %v", actor, err)
+		}
+
+		restored := &gameRouter{id: running.id, players: players, bots: running.bots}
+		if restored.game, err = running.game.Copy(); err != nil {
+			t.Fatalf("failed to copy game: %v", err)
+		}
+		again, err := restored.botStrategy(actor)
+		if err != nil {
+			t.Fatalf("failed to create restored strategy for %s: %v", actor, err)
+		}
+		if running.game.Phase.Market() {
+			turn := strategy.MarketTurn(running.game, actor)
+			if other := again.MarketTurn(restored.game, actor); !reflect.DeepEqual(turn, other) {
+				t.Errorf("%s chose %+v after being restored instead of %+v", actor, other, turn)
+			}
+		}
+
+		if errs := bots.Play(strategy, running.game); len(errs) > 0 {
+			t.Fatalf("%s failed to take their turn: %v", actor, errs)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gameState"
+)
+
+// How often a comment is sent on idle event streams so proxies don't close the connection, and
+// how many events can be waiting for a subscriber before it is considered too slow to keep.
+const (
+	keepAliveInterval = 30 * time.Second
+	eventBufferSize   = 16
+)
+
+// The gameEvent struct is what gets pushed to every subscriber after the game changes. It always
+// contains a full snapshot of the game so clients never have to fetch anything else to catch up,
+// along with the action that caused the change if there was one.
+type gameEvent struct {
+	Type   string            `json:"type"`
+	Action *gameState.Action `json:"action,omitempty"`
+	gameSnapshot
+}
+
+// The event types tell clients why the game changed. A phase event is sent instead of a turn
+// event whenever the action ended the market or business phase. Games waiting for players send a
+// join event whenever a seat is filled, and a start event once the last one is.
+const (
+	snapshotEvent = "snapshot"
+	joinEvent     = "join"
+	startEvent    = "start"
+	turnEvent     = "turn"
+	phaseEvent    = "phase"
+	undoEvent     = "undo"
+)
+
+// phaseKey identifies the current phase so we can tell when an action started a new one.
+func phaseKey(game *gameState.Game) string {
+	return fmt.Sprintf("%d-%v", game.Round, game.Phase)
+}
+
+// encodeEvent formats the current state of the game as a server-sent event. It must be called
+// with the lock held.
+func (r *gameRouter) encodeEvent(eventType string) ([]byte, error) {
+	event := gameEvent{Type: eventType, gameSnapshot: r.snapshot()}
+	if r.game != nil && eventType != snapshotEvent && eventType != undoEvent &&
+		len(r.game.Log) > 0 {
+		event.Action = &r.game.Log[len(r.game.Log)-1]
+	}
+
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", eventType, buf)), nil
+}
+
+// publish sends an event describing the current state of the game to every subscriber. It must be
+// called with the lock held so events are sent in the same order the game changed. Subscribers
+// that have fallen too far behind are dropped, which closes their stream so the client can
+// reconnect and start over with a fresh snapshot.
+func (r *gameRouter) publish(eventType string) {
+	if r.game != nil {
+		key := phaseKey(r.game)
+		if eventType == turnEvent && key != r.lastPhase {
+			eventType = phaseEvent
+		}
+		r.lastPhase = key
+	}
+
+	r.eventLock.Lock()
+	defer r.eventLock.Unlock()
+	if len(r.subscribers) == 0 {
+		return
+	}
+	buf, err := r.encodeEvent(eventType)
+	if err != nil {
+		log.Printf("failed to encode %s event for game %q: %v", eventType, r.id, err)
+		return
+	}
+	for sub := range r.subscribers {
+		select {
+		case sub <- buf:
+		default:
+			delete(r.subscribers, sub)
+			close(sub)
+		}
+	}
+}
+
+// subscribe registers a new event stream and returns the snapshot it should start with. The
+// snapshot is created with the lock held so no events can be missed between it and the stream.
+func (r *gameRouter) subscribe() (chan []byte, []byte, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	initial, err := r.encodeEvent(snapshotEvent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := make(chan []byte, eventBufferSize)
+	r.eventLock.Lock()
+	defer r.eventLock.Unlock()
+	if r.subscribers == nil {
+		r.subscribers = make(map[chan []byte]bool)
+	}
+	r.subscribers[sub] = true
+	return sub, initial, nil
+}
+
+func (r *gameRouter) unsubscribe(sub chan []byte) {
+	r.eventLock.Lock()
+	defer r.eventLock.Unlock()
+	if r.subscribers[sub] {
+		delete(r.subscribers, sub)
+		close(sub)
+	}
+}
+
+// closeEvents ends every open event stream, which happens when the game is deleted.
+func (r *gameRouter) closeEvents() {
+	r.eventLock.Lock()
+	defer r.eventLock.Unlock()
+	for sub := range r.subscribers {
+		delete(r.subscribers, sub)
+		close(sub)
+	}
+}
+
+// streamEvents pushes an event to the client every time the game changes using server-sent
+// events. The first event is always a snapshot of the current game.
+func (r *gameRouter) streamEvents(writer http.ResponseWriter, request *http.Request) {
+	sub, initial, err := r.subscribe()
+	if err != nil {
+		writeJson(&jsonResponse{status: 500, Errors: []string{err.Error()}}, writer)
+		return
+	}
+	defer r.unsubscribe(sub)
+
+	// The stream stays open much longer than the server's write timeout allows, so we need to
+	// clear the deadline. Not every writer supports this, which is fine since they won't have one.
+	control := http.NewResponseController(writer)
+	control.SetWriteDeadline(time.Time{})
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(200)
+	writer.Write(initial)
+	control.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case buf, open := <-sub:
+			if !open {
+				return
+			}
+			if _, err := writer.Write(buf); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := writer.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		case <-request.Context().Done():
+			return
+		}
+		control.Flush()
+	}
+}
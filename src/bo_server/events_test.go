@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gameState"
+)
+
+// The phase and turn in the game state are only marshalled for display, so the tests decode the
+// events into their own struct with the fields they need.
+type testEvent struct {
+	Type   string            `json:"type"`
+	Action *gameState.Action `json:"action"`
+	State  struct {
+		Round int    `json:"round"`
+		Turn  string `json:"turn"`
+	} `json:"state"`
+}
+
+// readEvent reads the next server-sent event from the stream, skipping any comments.
+func readEvent(t *testing.T, reader *bufio.Reader) (string, *testEvent) {
+	var name, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" && name != "" {
+			break
+		} else if strings.HasPrefix(line, "event: ") {
+			name = strings.TrimPrefix(line, "event: ")
+		} else if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	event := new(testEvent)
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		t.Fatalf("%s event has invalid data %q: %v", name, data, err)
+	} else if event.Type != name {
+		t.Errorf("%s event has type %q", name, event.Type)
+	}
+	return name, event
+}
+
+// TestEvents checks to make sure every subscriber gets a snapshot when it connects and an event
+// for every accepted action, including the actions that end a phase.
+func TestEvents(t *testing.T) {
+	handler, router := newTestRouter(t, "events", []string{"1st", "2nd"})
+	defer removeTestGame("events")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	readers := make([]*bufio.Reader, 2)
+	for ind := range readers {
+		request, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/events/events", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("failed to connect to event stream: %v", err)
+		}
+		defer response.Body.Close()
+		if ct := response.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("event stream has content type %q", ct)
+		}
+		readers[ind] = bufio.NewReader(response.Body)
+	}
+
+	expectEvent := func(expected string) *testEvent {
+		var result *testEvent
+		for ind, reader := range readers {
+			name, event := readEvent(t, reader)
+			if name != expected {
+				t.Errorf("subscriber %d got %s event, expected %s", ind, name, expected)
+			}
+			result = event
+		}
+		return result
+	}
+	expectEvent(snapshotEvent)
+
+	player := passTurn(t, handler, router)
+	if event := expectEvent(turnEvent); event.Action == nil || event.Action.Player != player {
+		t.Errorf("turn event has action %+v, expected %s's pass", event.Action, player)
+	} else if event.State.Turn == player {
+		t.Errorf("turn event still has %s's turn after they passed", player)
+	}
+
+	token := seatToken(router, player)
+	status, _ := doSeatRequest(t, handler, token, "POST", "/events/undo", struct{}{})
+	if status != 200 {
+		t.Fatalf("undo returned status %d", status)
+	}
+	if event := expectEvent(undoEvent); event.State.Turn != player {
+		t.Errorf("undo event has %s's turn, expected %s's", event.State.Turn, player)
+	}
+
+	passTurn(t, handler, router)
+	expectEvent(turnEvent)
+	passTurn(t, handler, router)
+	if event := expectEvent(phaseEvent); event.State.Round != 2 {
+		t.Errorf("phase event is for round %d after everyone passed", event.State.Round)
+	}
+}
+
+// TestWaitingEvents checks to make sure subscribers to a game that is waiting for players hear
+// about every player who joins and about the game starting.
+func TestWaitingEvents(t *testing.T) {
+	handler := newLobbyRouter()
+	defer removeTestGame("waiting_events")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	body := map[string]interface{}{"id": "waiting_events", "players": []string{"1st"}, "seats": 3}
+	if status, resp := doRequest(t, handler, "POST", "/games", body); status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	url := server.URL + "/waiting_events/events"
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("failed to connect to event stream: %v", err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	if name, _ := readEvent(t, reader); name != snapshotEvent {
+		t.Errorf("waiting game sent %s event first, expected %s", name, snapshotEvent)
+	}
+
+	for _, name := range []string{"2nd", "3rd"} {
+		join := map[string]string{"player_name": name}
+		status, resp := doRequest(t, handler, "POST", "/games/waiting_events/join", join)
+		if status != 200 {
+			t.Fatalf("joining game returned status %d: %+v", status, resp)
+		}
+	}
+	if name, _ := readEvent(t, reader); name != joinEvent {
+		t.Errorf("second player joining sent %s event, expected %s", name, joinEvent)
+	}
+	if name, event := readEvent(t, reader); name != startEvent {
+		t.Errorf("last player joining sent %s event, expected %s", name, startEvent)
+	} else if event.State.Turn == "" || event.State.Round != 1 {
+		t.Errorf("start event has turn %q in round %d, expected someone's turn in round 1",
+			event.State.Turn, event.State.Round)
+	}
+}
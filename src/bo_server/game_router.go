@@ -1,116 +1,492 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"math/rand"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"boardInfo"
 	"gameState"
 )
 
 var activeGames = map[string]*gameRouter{}
 var mapLock sync.RWMutex
 
+// debugInvariants makes every game check its invariants after each action, logging any problems.
+// It's meant for finding bugs in the rules, so the actions are never rejected because of them.
+var debugInvariants bool
+
+// The gameRouter serves all of the routes for a single game. The game itself is not safe for
+// concurrent use, so every handler must hold the lock while it accesses the game. The read lock
+// is held until the response is fully marshalled so reads always see a consistent snapshot.
+//
+// Games can be created with open seats, in which case the game itself isn't created until enough
+// players have joined. Until then players can only take their seats and watch the game's state.
+//
+// The bots map the players controlled by the computer to the names of their strategies, which are
+// created again for each of their turns.
+//
+// The clock is only set if the game has a turn clock. Each turn's timer is numbered so a timer that
+// fires just as its turn ends can tell it is no longer needed.
+//
+// The subscribers are the channels for every open event stream. They have their own lock so the
+// streams never need to hold the game lock while they wait for events.
 type gameRouter struct {
 	http.Handler
+	id   string
+	lock sync.RWMutex
 	game *gameState.Game
+
+	seats   int
+	players []string
+	tokens  map[string]string
+	deleted bool
+
+	bots map[string]string
+
+	undoConsent   bool
+	pendingUndo   *undoRequest
+	endConditions gameState.EndConditions
+	options       gameState.GameOptions
+
+	clock      *turnClock
+	clockTimer *time.Timer
+	clockTurn  int
+
+	eventLock   sync.Mutex
+	subscribers map[chan []byte]bool
+	lastPhase   string
 }
 
-func (r gameRouter) getGameState(writer http.ResponseWriter, request *http.Request) {
-	writeJson(&jsonResponse{Result: r.game.GlobalState}, writer)
+func (r *gameRouter) record() *gameRecord {
+	return &gameRecord{
+		Seats:   r.seats,
+		Players: r.players,
+		Tokens:  r.tokens,
+		Game:    r.game,
+		Bots:    r.bots,
+
+		UndoConsent:   r.undoConsent,
+		EndConditions: r.endConditions,
+		Options:       r.options,
+		Clock:         r.clock,
+	}
 }
-func (r gameRouter) getPlayers(writer http.ResponseWriter, request *http.Request) {
-	writeJson(&jsonResponse{Result: r.game.Players}, writer)
+
+// save writes the game through to the store. It must be called with the lock held after every
+// successful action. If the save fails the action has still been performed, but we let the user
+// know it might be lost if the server restarts. Any pending undo request is cancelled, since it
+// was for an action that is no longer the most recent one, and the turn clock moves on to
+// whoever needs to act next.
+func (r *gameRouter) save(resp *jsonResponse) {
+	if r.deleted {
+		return
+	}
+	r.pendingUndo = nil
+	r.resetClock()
+	if debugInvariants && r.game != nil {
+		for _, err := range r.game.CheckInvariants() {
+			log.Printf("game %q broke an invariant after %d actions: %v", r.id, len(r.game.Log),
+				err)
+		}
+	}
+	if err := store.Save(r.id, r.record()); err != nil {
+		log.Printf("failed to save game %q: %v", r.id, err)
+		resp.status = 500
+		resp.Errors = []string{fmt.Sprintf("failed to save game: %v", err)}
+	}
 }
-func (r gameRouter) getCompanies(writer http.ResponseWriter, request *http.Request) {
-	writeJson(&jsonResponse{Result: r.game.Companies}, writer)
+
+func (r *gameRouter) getGameState(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	writeJson(&jsonResponse{Result: r.stateView()}, writer)
+}
+func (r *gameRouter) getPlayers(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	writeJson(&jsonResponse{Result: r.snapshot().Players}, writer)
+}
+func (r *gameRouter) getCompanies(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	writeJson(&jsonResponse{Result: r.snapshot().Companies}, writer)
 }
 
-func (r gameRouter) takeMarketTurn(writer http.ResponseWriter, request *http.Request) {
+// getBoard describes every hex on the board the game is played on. The board is picked when the
+// game is created, so it's available before the game starts.
+func (r *gameRouter) getBoard(writer http.ResponseWriter, request *http.Request) {
 	resp := jsonResponse{}
 	defer writeJson(&resp, writer)
 
-	var body struct {
-		Player string `json:"player_name"`
-		gameState.MarketTurn
+	board := boardInfo.FindBoard(r.options.Board)
+	if board == nil {
+		resp.status = 500
+		resp.Errors = []string{fmt.Sprintf("no board named %q", r.options.Board)}
+	} else if buf, err := board.JsonMap(); err != nil {
+		resp.status = 500
+		resp.Errors = []string{err.Error()}
+	} else {
+		resp.Result = (*json.RawMessage)(&buf)
 	}
+}
+
+// getServicePlan explains which cities the current company would service if its president lets
+// the game choose them automatically.
+func (r *gameRouter) getServicePlan(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if plan, err := r.game.ServicePlan(); err != nil {
+		writeJson(&jsonResponse{status: 409, Errors: []string{err.Error()}}, writer)
+	} else {
+		writeJson(&jsonResponse{Result: plan}, writer)
+	}
+}
+
+// getLegalMoves lists everything the player whose turn it is can do.
+func (r *gameRouter) getLegalMoves(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if moves, err := r.game.LegalMoves(); err != nil {
+		writeJson(&jsonResponse{status: 409, Errors: []string{err.Error()}}, writer)
+	} else {
+		writeJson(&jsonResponse{Result: moves}, writer)
+	}
+}
+
+// isDryRun checks if the request only wants the turn to be validated, without performing it.
+func isDryRun(request *http.Request) bool {
+	return request.URL.Query().Get("dry_run") == "true"
+}
+
+func (r *gameRouter) takeMarketTurn(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	var body gameState.MarketTurn
 	if err := readBody(&body, request); err != nil {
 		resp.status = 400
 		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
 		return
 	}
 
-	if errs := r.game.PerformMarketTurn(body.Player, body.MarketTurn); len(errs) > 0 {
-		resp.status = 400
-		resp.Errors = convertErrors(errs)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	player := r.seatPlayer(request)
+	if player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to take a turn"}
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if isDryRun(request) {
+		if projection, errs := r.game.ValidateMarketTurn(player, body); len(errs) > 0 {
+			resp.setRuleErrors(errs)
+		} else {
+			resp.Result = projection
+		}
+	} else if errs := r.game.PerformMarketTurn(player, body); len(errs) > 0 {
+		resp.setRuleErrors(errs)
+	} else {
+		r.save(&resp)
+		r.publish(turnEvent)
+		r.playBots()
 	}
 }
 
-func (r gameRouter) takeBusinessTurnOne(writer http.ResponseWriter, request *http.Request) {
+func (r *gameRouter) takeBusinessTurnOne(writer http.ResponseWriter, request *http.Request) {
 	resp := jsonResponse{}
 	defer writeJson(&resp, writer)
 
-	var body struct {
-		Player string `json:"player_name"`
-		gameState.CompanyInventory
-	}
+	var body gameState.CompanyInventory
 	if err := readBody(&body, request); err != nil {
 		resp.status = 400
 		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
 		return
 	}
 
-	if errs := r.game.UpdateCompanyInventory(body.Player, body.CompanyInventory); len(errs) > 0 {
-		resp.status = 400
-		resp.Errors = convertErrors(errs)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	player := r.seatPlayer(request)
+	if player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to take a turn"}
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if isDryRun(request) {
+		if projection, errs := r.game.ValidateCompanyInventory(player, body); len(errs) > 0 {
+			resp.setRuleErrors(errs)
+		} else {
+			resp.Result = projection
+		}
+	} else if errs := r.game.UpdateCompanyInventory(player, body); len(errs) > 0 {
+		resp.setRuleErrors(errs)
+	} else {
+		r.save(&resp)
+		r.publish(turnEvent)
+		r.playBots()
 	}
 }
 
-func (r gameRouter) takeBusinessTurnTwo(writer http.ResponseWriter, request *http.Request) {
+func (r *gameRouter) takeBusinessTurnTwo(writer http.ResponseWriter, request *http.Request) {
 	resp := jsonResponse{}
 	defer writeJson(&resp, writer)
 
-	var body struct {
-		Player string `json:"player_name"`
-		gameState.CompanyEarnings
-	}
+	var body gameState.CompanyEarnings
 	if err := readBody(&body, request); err != nil {
 		resp.status = 400
 		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
 		return
 	}
 
-	if errs := r.game.HandleCompanyEarnings(body.Player, body.CompanyEarnings); len(errs) > 0 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	player := r.seatPlayer(request)
+	if player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to take a turn"}
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if errs := r.game.HandleCompanyEarnings(player, body); len(errs) > 0 {
+		resp.setRuleErrors(errs)
+	} else {
+		r.save(&resp)
+		r.publish(turnEvent)
+		r.playBots()
+	}
+}
+
+// previewBusinessTurnTwo shows the president what would happen to the company and the players if
+// they paid dividends or retained the earnings, without changing the game.
+func (r *gameRouter) previewBusinessTurnTwo(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	var body gameState.CompanyEarnings
+	if err := readBody(&body, request); err != nil {
 		resp.status = 400
-		resp.Errors = convertErrors(errs)
+		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
+		return
 	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	player := r.seatPlayer(request)
+	if player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to preview a turn"}
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if preview, errs := r.game.PreviewCompanyEarnings(player, body); len(errs) > 0 {
+		resp.setRuleErrors(errs)
+	} else {
+		resp.Result = preview
+	}
+}
+
+// The routes that work before a game starts. Players need to take their seats while they wait, and
+// the read-only views show how many more players the game is waiting for.
+var waitingRoutes = map[string]bool{
+	"/seat":      true,
+	"/state":     true,
+	"/players":   true,
+	"/companies": true,
+	"/snapshot":  true,
+	"/board":     true,
+	"/events":    true,
 }
 
-func addNewGame(gameId string, playerNames []string) error {
+// requireStarted rejects any requests for a game that is still waiting for players to join,
+// other than the waiting routes.
+func (r *gameRouter) requireStarted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == "GET" && waitingRoutes[request.URL.Path] {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		r.lock.RLock()
+		open := r.seats - len(r.players)
+		r.lock.RUnlock()
+
+		if open > 0 {
+			data := jsonResponse{
+				status: 409,
+				Errors: []string{fmt.Sprintf("game %q is waiting for %d more players", r.id, open)},
+			}
+			writeJson(&data, writer)
+		} else {
+			next.ServeHTTP(writer, request)
+		}
+	})
+}
+
+// join adds a player to a game with open seats, starting the game if all seats are filled. It
+// returns the secret token the player must use to take their turns. It must be called with the
+// lock held.
+func (r *gameRouter) join(playerName string) (string, error) {
+	if r.game != nil {
+		return "", fmt.Errorf("game %q has already started", r.id)
+	} else if playerName == "" {
+		return "", fmt.Errorf("player name is required to join a game")
+	}
+	for _, name := range r.players {
+		if name == playerName {
+			return "", fmt.Errorf("game %q already has a player named %q", r.id, playerName)
+		}
+	}
+
+	token, err := newSeatToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to create seat token: %v", err)
+	}
+	if r.tokens == nil {
+		r.tokens = make(map[string]string, r.seats)
+	}
+	players := append(r.players[:len(r.players):len(r.players)], playerName)
+	if len(players) >= r.seats {
+		game, errs := gameState.NewCustomGame(players, rand.Int63(), r.options)
+		if len(errs) > 0 {
+			return "", fmt.Errorf("failed to start game %q: %v", r.id, errs)
+		}
+		r.game = game
+		r.game.EndConditions = r.endConditions
+		r.lastPhase = phaseKey(r.game)
+	}
+	r.tokens[token] = playerName
+	r.players = players
+	return token, nil
+}
+
+// addNewGame creates a new game with the provided players. If there are more seats than players
+// the game will wait until enough other players join before starting. If undoConsent is set every
+// player must approve before an action can be undone, and if the clock settings have any times
+// set every turn is timed. The game is played using the options' rules. It returns the new game
+// along with the seat tokens for all of the provided players, keyed by the player names.
+func addNewGame(gameId string, playerNames []string, seats int, undoConsent bool,
+	endConditions gameState.EndConditions, options gameState.GameOptions,
+	clock clockSettings) (*gameRouter, map[string]string, error) {
 	mapLock.Lock()
 	defer mapLock.Unlock()
 
 	if _, exists := activeGames[gameId]; exists {
-		return fmt.Errorf("game with id %q already exists", gameId)
+		return nil, nil, fmt.Errorf("game with id %q already exists", gameId)
+	}
+	if seats < len(playerNames) {
+		seats = len(playerNames)
+	}
+	if seats == 0 {
+		return nil, nil, fmt.Errorf("game must have at least one seat")
+	}
+	if errs := options.Validate(seats); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid game options: %v", errs)
+	}
+	gameClock, err := newTurnClock(clock)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	router := mux.NewRouter()
 	result := &gameRouter{
-		Handler: http.StripPrefix("/"+gameId, router),
-		game:    gameState.NewGame(playerNames),
+		id:    gameId,
+		seats: seats,
+
+		undoConsent:   undoConsent,
+		endConditions: endConditions,
+		options:       options,
+		clock:         gameClock,
+	}
+	tokens := make(map[string]string, len(playerNames))
+	for _, name := range playerNames {
+		if token, err := result.join(name); err != nil {
+			return nil, nil, err
+		} else {
+			tokens[name] = token
+		}
+	}
+	if gameClock != nil {
+		result.startClock(time.Now())
+	}
+	if err := store.Save(gameId, result.record()); err != nil {
+		return nil, nil, fmt.Errorf("failed to save game %q: %v", gameId, err)
+	}
+	registerGame(result)
+	return result, tokens, nil
+}
+
+// loadGames restores all of the games in the store into the active games.
+func loadGames() error {
+	games, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	mapLock.Lock()
+	defer mapLock.Unlock()
+	for gameId, record := range games {
+		if boardInfo.FindBoard(record.Options.Board) == nil {
+			return fmt.Errorf("game %q is played on the %q board, which hasn't been loaded",
+				gameId, record.Options.Board)
+		}
+		game := &gameRouter{
+			id:      gameId,
+			game:    record.Game,
+			seats:   record.Seats,
+			players: record.Players,
+			tokens:  record.Tokens,
+			bots:    record.Bots,
+
+			undoConsent:   record.UndoConsent,
+			endConditions: record.EndConditions,
+			options:       record.Options,
+			clock:         record.Clock,
+		}
+		registerGame(game)
+
+		// Give any bot that failed to take its turn before the restart another chance, and
+		// restart the current turn so nobody is charged for the time the server was down.
+		game.lock.Lock()
+		if game.clock != nil {
+			game.startClock(time.Now())
+		}
+		game.playBots()
+		game.lock.Unlock()
+	}
+	return nil
+}
+
+// registerGame creates the routes for a game and adds it to the active games. It must be called
+// with the mapLock held.
+func registerGame(result *gameRouter) {
+	router := mux.NewRouter()
+	result.Handler = http.StripPrefix("/"+result.id, result.requireStarted(router))
+	activeGames[result.id] = result
+	if result.game != nil {
+		result.lastPhase = phaseKey(result.game)
 	}
-	activeGames[gameId] = result
 
 	router.HandleFunc("/state", result.getGameState)
 	router.HandleFunc("/players", result.getPlayers)
 	router.HandleFunc("/companies", result.getCompanies)
+	router.HandleFunc("/snapshot", result.getSnapshot)
+	router.HandleFunc("/board", result.getBoard)
+	router.HandleFunc("/seat", result.takeSeat)
+	router.HandleFunc("/events", result.streamEvents)
+	router.HandleFunc("/service_plan", result.getServicePlan)
+	router.HandleFunc("/legal_moves", result.getLegalMoves)
+	router.Methods("GET").Path("/undo").HandlerFunc(result.getUndo)
 
 	router.HandleFunc("/market_turn", result.takeMarketTurn)
 	router.HandleFunc("/business_turn_one", result.takeBusinessTurnOne)
 	router.HandleFunc("/business_turn_two", result.takeBusinessTurnTwo)
-	return nil
+	router.HandleFunc("/business_turn_two/preview", result.previewBusinessTurnTwo)
+	router.Methods("POST").Path("/undo").HandlerFunc(result.requestUndo)
+	router.HandleFunc("/undo/approve", result.approveUndo)
+	router.HandleFunc("/undo/reject", result.rejectUndo)
 }
 
 func initializeGameRoutes(router *mux.Router) {
@@ -118,19 +494,33 @@ func initializeGameRoutes(router *mux.Router) {
 	getter.HandleFunc("/{gameId}/state", serveGameContent)
 	getter.HandleFunc("/{gameId}/players", serveGameContent)
 	getter.HandleFunc("/{gameId}/companies", serveGameContent)
+	getter.HandleFunc("/{gameId}/snapshot", serveGameContent)
+	getter.HandleFunc("/{gameId}/board", serveGameContent)
+	getter.HandleFunc("/{gameId}/seat", serveGameContent)
+	getter.HandleFunc("/{gameId}/events", serveGameContent)
+	getter.HandleFunc("/{gameId}/service_plan", serveGameContent)
+	getter.HandleFunc("/{gameId}/legal_moves", serveGameContent)
+	getter.HandleFunc("/{gameId}/undo", serveGameContent)
 
 	poster := router.Methods("POST").Subrouter()
 	poster.HandleFunc("/{gameId}/market_turn", serveGameContent)
 	poster.HandleFunc("/{gameId}/business_turn_one", serveGameContent)
 	poster.HandleFunc("/{gameId}/business_turn_two", serveGameContent)
+	poster.HandleFunc("/{gameId}/business_turn_two/preview", serveGameContent)
+	poster.HandleFunc("/{gameId}/undo", serveGameContent)
+	poster.HandleFunc("/{gameId}/undo/approve", serveGameContent)
+	poster.HandleFunc("/{gameId}/undo/reject", serveGameContent)
 }
 
+// serveGameContent passes the request on to the game's own router. The map lock is released
+// before the request is served, since event streams stay open for as long as the client wants.
 func serveGameContent(writer http.ResponseWriter, request *http.Request) {
+	gameId := mux.Vars(request)["gameId"]
 	mapLock.RLock()
-	defer mapLock.RUnlock()
+	game, exists := activeGames[gameId]
+	mapLock.RUnlock()
 
-	gameId := mux.Vars(request)["gameId"]
-	if game, exists := activeGames[gameId]; !exists {
+	if !exists {
 		data := jsonResponse{
 			status: 404,
 			Errors: []string{fmt.Sprintf("no active game with id %q", gameId)},
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"boardInfo"
+	"gameState"
+)
+
+func newTestRouter(t *testing.T, gameId string, names []string) (http.Handler, *gameRouter) {
+	_, _, err := addNewGame(gameId, names, 0, false, gameState.DefaultEndConditions,
+		gameState.DefaultGameOptions, clockSettings{})
+	if err != nil {
+		t.Fatalf("failed to create game %q: %v", gameId, err)
+	}
+	router := mux.NewRouter()
+	initializeLobbyRoutes(router)
+	initializeGameRoutes(router)
+
+	mapLock.RLock()
+	defer mapLock.RUnlock()
+	return router, activeGames[gameId]
+}
+
+// seatToken finds the token for a player's seat, which the tests need to take turns.
+func seatToken(router *gameRouter, playerName string) string {
+	router.lock.RLock()
+	defer router.lock.RUnlock()
+	for token, name := range router.tokens {
+		if name == playerName {
+			return token
+		}
+	}
+	return ""
+}
+
+func removeTestGame(gameId string) {
+	mapLock.Lock()
+	defer mapLock.Unlock()
+	delete(activeGames, gameId)
+}
+
+func doRequest(t *testing.T, handler http.Handler, method, url string,
+	body interface{}) (int, *jsonResponse) {
+	return doSeatRequest(t, handler, "", method, url, body)
+}
+
+// doSeatRequest serves the request directly instead of going through an actual HTTP server. Aside
+// from being faster this avoids synchronization in the server that would hide data races. If the
+// token isn't empty the request is made as the player with that seat.
+func doSeatRequest(t *testing.T, handler http.Handler, token, method, url string,
+	body interface{}) (int, *jsonResponse) {
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatalf("failed to encode request body %+v: %v", body, err)
+		}
+	}
+
+	request := httptest.NewRequest(method, url, &reader)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var result jsonResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Errorf("%s request to %s returned invalid JSON: %v", method, url, err)
+		return recorder.Code, nil
+	}
+	return recorder.Code, &result
+}
+
+// TestConcurrentRequests has every player hammer all of the game routes at the same time. It
+// doesn't check much about the results, it's meant to be run with the race detector to make sure
+// the game is never accessed without the proper locks held.
+func TestConcurrentRequests(t *testing.T) {
+	playerNames := []string{"1st", "2nd", "3rd", "4th"}
+	handler, router := newTestRouter(t, "concurrent", playerNames)
+	defer removeTestGame("concurrent")
+
+	// Give one of the players a profitable company so the game continues cycling between the
+	// market and business phases instead of running out of companies to operate.
+	const companyName = "New York Central"
+	company := router.game.Companies[companyName]
+	company.President = playerNames[0]
+	company.StockPrice = boardInfo.StartingStockPrices(1)[0]
+	company.HeldStock = 5
+	company.BuiltTrack = []string{router.game.Board().StartingLocation(companyName)}
+	company.Equipment[0] = 1
+	router.game.Players[playerNames[0]].Stocks[companyName] = 5
+
+	const gameUrl = "/concurrent"
+	var wg sync.WaitGroup
+	for _, name := range playerNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			token, empty := seatToken(router, name), struct{}{}
+			for ind := 0; ind < 50; ind += 1 {
+				switch rand.Intn(7) {
+				case 0:
+					doRequest(t, handler, "GET", gameUrl+"/state", nil)
+				case 1:
+					doRequest(t, handler, "GET", gameUrl+"/players", nil)
+				case 2:
+					doRequest(t, handler, "GET", gameUrl+"/companies", nil)
+				case 3:
+					doSeatRequest(t, handler, token, "POST", gameUrl+"/market_turn", empty)
+				case 4:
+					doSeatRequest(t, handler, token, "POST", gameUrl+"/business_turn_one", empty)
+				case 5:
+					doSeatRequest(t, handler, token, "POST", gameUrl+"/business_turn_two", empty)
+				case 6:
+					doRequest(t, handler, "GET", gameUrl+"/snapshot", nil)
+				}
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if status, resp := doRequest(t, handler, "GET", gameUrl+"/state", nil); status != 200 {
+		t.Errorf("game state returned status %d after concurrent requests: %+v", status, resp)
+	}
+}
+
+// TestStateTurnOrder checks to make sure the state includes the whole turn order, while the turn
+// is still just the current player.
+func TestStateTurnOrder(t *testing.T) {
+	handler, router := newTestRouter(t, "turn_order", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("turn_order")
+	passTurn(t, handler, router)
+
+	status, resp := doRequest(t, handler, "GET", "/turn_order/state", nil)
+	if status != 200 {
+		t.Fatalf("game state returned status %d: %+v", status, resp)
+	}
+	state, _ := resp.Result.(map[string]interface{})
+	turnOrder, _ := state["turn_order"].(map[string]interface{})
+	current := router.game.TurnManager.Current()
+	if state["turn"] != current || turnOrder["current"] != current {
+		t.Errorf("state has turn %v and turn order %v, expected %s", state["turn"], turnOrder,
+			current)
+	}
+	if turnOrder["passes"] != float64(1) || turnOrder["passes_needed"] != float64(3) {
+		t.Errorf("turn order has passes %v of %v, expected 1 of 3", turnOrder["passes"],
+			turnOrder["passes_needed"])
+	}
+
+	order, _ := turnOrder["order"].([]interface{})
+	if len(order) != len(router.game.TurnManager.Order) {
+		t.Fatalf("turn order is %v, expected %v", order, router.game.TurnManager.Order)
+	}
+	for ind, name := range router.game.TurnManager.Order {
+		position, _ := order[ind].(map[string]interface{})
+		if position["name"] != name || position["reason"] == "" {
+			t.Errorf("position %d in the turn order is %v, expected %s", ind, position, name)
+		}
+	}
+}
+
+// TestMissingGame checks to make sure requests for games that don't exist are rejected.
+func TestMissingGame(t *testing.T) {
+	handler, _ := newTestRouter(t, "missing", []string{"1st", "2nd"})
+	defer removeTestGame("missing")
+
+	for _, route := range []string{"state", "players", "companies"} {
+		url := fmt.Sprintf("/not_a_game/%s", route)
+		if status, _ := doRequest(t, handler, "GET", url, nil); status != 404 {
+			t.Errorf("GET %s returned status %d, expected 404", url, status)
+		}
+	}
+}
+
+// TestServicePlanRoute checks to make sure the service plan is only available when a company is
+// ready to handle its earnings.
+func TestServicePlanRoute(t *testing.T) {
+	handler, _ := newTestRouter(t, "service_plan", []string{"1st", "2nd"})
+	defer removeTestGame("service_plan")
+
+	if status, _ := doRequest(t, handler, "GET", "/service_plan/service_plan", nil); status != 409 {
+		t.Errorf("service plan during the market phase returned status %d, expected 409", status)
+	}
+}
+
+// TestEarningsPreviewRoute checks to make sure the president can preview their earnings without
+// the game changing.
+func TestEarningsPreviewRoute(t *testing.T) {
+	const url = "/earnings_preview/business_turn_two/preview"
+	handler, router := newTestRouter(t, "earnings_preview", []string{"1st", "2nd"})
+	defer removeTestGame("earnings_preview")
+
+	// Have the first player start a company with a train so it is ready for its earnings.
+	game := router.game
+	president := game.TurnManager.Current()
+	purchase := gameState.MarketAction{
+		Company: "Baltimore & Ohio",
+		Count:   2,
+		Price:   boardInfo.StartingStockPrices(game.TechLevel)[0],
+	}
+	turn := gameState.MarketTurn{Purchase: &purchase}
+	if errs := game.PerformMarketTurn(president, turn); errs != nil {
+		t.Fatalf("failed to start company: %v", errs)
+	}
+	for game.Phase.Market() {
+		game.PerformMarketTurn(game.TurnManager.Current(), gameState.MarketTurn{})
+	}
+	update := gameState.CompanyInventory{Buy: 1}
+	if errs := game.UpdateCompanyInventory(president, update); errs != nil {
+		t.Fatalf("failed to buy train: %v", errs)
+	}
+
+	logLength := len(game.Log)
+	if status, _ := doRequest(t, handler, "POST", url, struct{}{}); status != 401 {
+		t.Errorf("preview without token returned status %d, expected 401", status)
+	}
+	token := seatToken(router, president)
+	status, resp := doSeatRequest(t, handler, token, "POST", url, struct{}{})
+	if status != 200 {
+		t.Fatalf("preview returned status %d: %+v", status, resp)
+	}
+	preview, _ := resp.Result.(map[string]interface{})
+	if preview["dividends"] == nil || preview["retain"] == nil {
+		t.Errorf("preview is missing an option: %+v", resp.Result)
+	}
+	if len(game.Log) != logLength || game.Stage != "earnings" {
+		t.Error("previewing the earnings changed the game")
+	}
+}
+
+// TestDryRunRoute checks to make sure a dry run returns the projected effects of a turn, or all of
+// its errors, without taking the turn.
+func TestDryRunRoute(t *testing.T) {
+	const url = "/dry_run/market_turn?dry_run=true"
+	handler, router := newTestRouter(t, "dry_run", []string{"1st", "2nd"})
+	defer removeTestGame("dry_run")
+
+	game := router.game
+	current := game.TurnManager.Current()
+	price := boardInfo.StartingStockPrices(game.TechLevel)[0]
+	purchase := gameState.MarketAction{Company: "Baltimore & Ohio", Count: 2, Price: price}
+	turn := gameState.MarketTurn{Purchase: &purchase}
+	if status, _ := doRequest(t, handler, "POST", url, turn); status != 401 {
+		t.Errorf("dry run without token returned status %d, expected 401", status)
+	}
+
+	token := seatToken(router, current)
+	status, resp := doSeatRequest(t, handler, token, "POST", url, turn)
+	if status != 200 {
+		t.Fatalf("dry run returned status %d: %+v", status, resp)
+	}
+	projection, _ := resp.Result.(map[string]interface{})
+	treasury, _ := projection["treasury"].(map[string]interface{})
+	if treasury[purchase.Company] != float64(2*price) {
+		t.Errorf("dry run projected treasuries %v, expected $%d", treasury, 2*price)
+	}
+	if len(game.Log) != 0 || game.TurnManager.Current() != current {
+		t.Error("dry run took the turn")
+	}
+
+	purchase.Count = 20
+	if status, resp := doSeatRequest(t, handler, token, "POST", url, turn); status != 422 {
+		t.Errorf("invalid dry run returned status %d: %+v", status, resp)
+	}
+}
+
+// TestRuleErrorStatus checks to make sure rejected turns are reported with the codes for the rules
+// they broke, and with a status depending on whether they were taken at the wrong time.
+func TestRuleErrorStatus(t *testing.T) {
+	const url = "/rule_errors/market_turn"
+	handler, router := newTestRouter(t, "rule_errors", []string{"1st", "2nd", "3rd", "4th"})
+	defer removeTestGame("rule_errors")
+
+	game := router.game
+	current := game.TurnManager.Current()
+	other := "1st"
+	if current == other {
+		other = "2nd"
+	}
+	price := boardInfo.StartingStockPrices(game.TechLevel)[0]
+	purchase := gameState.MarketAction{Company: "Baltimore & Ohio", Count: 10, Price: price}
+	turn := gameState.MarketTurn{Purchase: &purchase}
+
+	status, resp := doSeatRequest(t, handler, seatToken(router, other), "POST", url, turn)
+	if status != 409 {
+		t.Errorf("turn out of order returned status %d, expected 409", status)
+	} else if len(resp.ErrorDetails) != 1 {
+		t.Errorf("turn out of order returned error details %+v", resp.ErrorDetails)
+	} else if details := resp.ErrorDetails[0]; details.Code != gameState.CodeNotYourTurn ||
+		details.Player != other {
+		t.Errorf("turn out of order returned error details %+v", *details)
+	}
+
+	cash := game.Players[current].Cash
+	status, resp = doSeatRequest(t, handler, seatToken(router, current), "POST", url, turn)
+	if status != 422 {
+		t.Errorf("unaffordable purchase returned status %d, expected 422", status)
+	} else if len(resp.ErrorDetails) != 1 {
+		t.Errorf("unaffordable purchase returned error details %+v", resp.ErrorDetails)
+	} else if details := resp.ErrorDetails[0]; details.Code != gameState.CodeInsufficientCash ||
+		details.Amount != 10*price || details.Limit != cash {
+		t.Errorf("unaffordable purchase returned error details %+v", *details)
+	}
+	if len(resp.Errors) != len(resp.ErrorDetails) {
+		t.Errorf("returned errors %q without matching details", resp.Errors)
+	}
+}
+
+// TestLegalMovesRoute checks to make sure the legal moves are listed for the current player.
+func TestLegalMovesRoute(t *testing.T) {
+	handler, router := newTestRouter(t, "legal_moves", []string{"1st", "2nd"})
+	defer removeTestGame("legal_moves")
+
+	status, resp := doRequest(t, handler, "GET", "/legal_moves/legal_moves", nil)
+	if status != 200 {
+		t.Fatalf("legal moves returned status %d: %+v", status, resp)
+	}
+	moves, _ := resp.Result.(map[string]interface{})
+	if current := router.game.TurnManager.Current(); moves["player"] != current {
+		t.Errorf("legal moves are for %v, expected %s", moves["player"], current)
+	}
+	market, _ := moves["market"].(map[string]interface{})
+	if purchases, _ := market["purchases"].([]interface{}); len(purchases) == 0 {
+		t.Errorf("no purchases listed at the start of the game: %+v", moves)
+	}
+}
+
+// TestDebugInvariants checks to make sure broken invariants are logged after an action when the
+// server is debugging them.
+func TestDebugInvariants(t *testing.T) {
+	handler, router := newTestRouter(t, "debug_invariants", []string{"1st", "2nd"})
+	defer removeTestGame("debug_invariants")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	debugInvariants = true
+	defer func() { debugInvariants = false }()
+
+	passTurn(t, handler, router)
+	if buf.Len() > 0 {
+		t.Errorf("valid game logged problems: %s", buf.String())
+	}
+
+	router.lock.Lock()
+	router.game.Companies["Erie"].HeldStock += 1
+	router.lock.Unlock()
+	passTurn(t, handler, router)
+	if !strings.Contains(buf.String(), "broke an invariant") {
+		t.Errorf("extra share wasn't logged: %q", buf.String())
+	}
+}
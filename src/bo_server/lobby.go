@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"boardInfo"
+	"bots"
+	"gameState"
+)
+
+// Game IDs are used in the URL paths, so we limit what characters they can contain. The "games"
+// ID is reserved because it would conflict with the lobby's own routes.
+var validGameId = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+const reservedGameId = "games"
+
+// The gameSummary struct is what the lobby shows about each game. The fields describing the game
+// progress are only present once the game has started.
+type gameSummary struct {
+	Id      string   `json:"id"`
+	Players []string `json:"players"`
+	Seats   int      `json:"seats"`
+	Open    bool     `json:"open"`
+	Board   string   `json:"board"`
+
+	Round    int         `json:"round,omitempty"`
+	Phase    interface{} `json:"phase,omitempty"`
+	Turn     string      `json:"turn,omitempty"`
+	Finished bool        `json:"finished,omitempty"`
+}
+
+func (r *gameRouter) summary() gameSummary {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := gameSummary{
+		Id:      r.id,
+		Players: r.players,
+		Seats:   r.seats,
+		Open:    r.game == nil,
+		Board:   r.options.Board,
+	}
+	if result.Board == "" {
+		result.Board = boardInfo.StandardBoard
+	}
+	if r.game != nil {
+		result.Round = r.game.Round
+		result.Phase = r.game.Phase
+		result.Turn = r.game.TurnManager.Current()
+		result.Finished = r.game.Finished
+	}
+	return result
+}
+
+func randomGameId() string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	buf := make([]byte, 6)
+	for ind := range buf {
+		buf[ind] = chars[rand.Intn(len(chars))]
+	}
+	return string(buf)
+}
+
+func listGames(writer http.ResponseWriter, request *http.Request) {
+	mapLock.RLock()
+	defer mapLock.RUnlock()
+
+	result := make([]gameSummary, 0, len(activeGames))
+	for _, game := range activeGames {
+		result = append(result, game.summary())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	writeJson(&jsonResponse{Result: result}, writer)
+}
+
+// listBoards returns the names of the boards new games can be played on.
+func listBoards(writer http.ResponseWriter, request *http.Request) {
+	writeJson(&jsonResponse{Result: boardInfo.BoardNames()}, writer)
+}
+
+func createGame(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	var body struct {
+		Id      string   `json:"id"`
+		Players []string `json:"players"`
+		Seats   int      `json:"seats"`
+
+		UndoConsent   bool                     `json:"undo_consent"`
+		EndConditions *gameState.EndConditions `json:"end_conditions"`
+		Options       gameState.GameOptions    `json:"options"`
+		Clock         clockSettings            `json:"turn_clock"`
+	}
+	// Any options left out of the request keep their standard values.
+	body.Options = gameState.DefaultGameOptions
+	if err := readBody(&body, request); err != nil {
+		resp.status = 400
+		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
+		return
+	}
+
+	if body.Id == "" {
+		// The chance of a collision is small enough we don't need to worry about the map
+		// changing between this check and the game being added.
+		mapLock.RLock()
+		body.Id = randomGameId()
+		for activeGames[body.Id] != nil {
+			body.Id = randomGameId()
+		}
+		mapLock.RUnlock()
+	} else if !validGameId.MatchString(body.Id) || body.Id == reservedGameId {
+		resp.status = 400
+		resp.Errors = []string{fmt.Sprintf("invalid game id %q", body.Id)}
+		return
+	}
+
+	if body.EndConditions == nil {
+		body.EndConditions = &gameState.DefaultEndConditions
+	}
+	game, tokens, err := addNewGame(body.Id, body.Players, body.Seats, body.UndoConsent,
+		*body.EndConditions, body.Options, body.Clock)
+	if err != nil {
+		resp.status = 400
+		resp.Errors = []string{err.Error()}
+		return
+	}
+
+	resp.status = 201
+	resp.Result = struct {
+		gameSummary
+		Tokens map[string]string `json:"seat_tokens"`
+	}{game.summary(), tokens}
+}
+
+func joinGame(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	var body struct {
+		Player string `json:"player_name"`
+		Bot    string `json:"bot"`
+	}
+	if err := readBody(&body, request); err != nil {
+		resp.status = 400
+		resp.Errors = []string{fmt.Sprintf("invalid request: %v", err)}
+		return
+	} else if body.Player == "" {
+		resp.status = 400
+		resp.Errors = []string{"player name is required to join a game"}
+		return
+	} else if _, err := bots.New(body.Bot, 0); body.Bot != "" && err != nil {
+		resp.status = 400
+		resp.Errors = []string{err.Error()}
+		return
+	}
+
+	mapLock.RLock()
+	defer mapLock.RUnlock()
+	gameId := mux.Vars(request)["gameId"]
+	game := activeGames[gameId]
+	if game == nil {
+		resp.status = 404
+		resp.Errors = []string{fmt.Sprintf("no active game with id %q", gameId)}
+		return
+	}
+
+	var token string
+	var err error
+	game.lock.Lock()
+	if body.Bot != "" {
+		err = game.addBot(body.Player, body.Bot)
+	} else {
+		token, err = game.join(body.Player)
+	}
+	if err != nil {
+		resp.status = 409
+		resp.Errors = []string{err.Error()}
+	} else {
+		game.save(&resp)
+		if game.game == nil {
+			game.publish(joinEvent)
+		} else {
+			game.publish(startEvent)
+		}
+		game.playBots()
+	}
+	game.lock.Unlock()
+
+	if resp.status == 0 && body.Bot != "" {
+		resp.Result = game.summary()
+	} else if resp.status == 0 {
+		game.setSeatCookie(writer, token)
+		resp.Result = struct {
+			gameSummary
+			Token string `json:"seat_token"`
+		}{game.summary(), token}
+	}
+}
+
+func deleteGame(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	mapLock.Lock()
+	defer mapLock.Unlock()
+	gameId := mux.Vars(request)["gameId"]
+	game, exists := activeGames[gameId]
+	if !exists {
+		resp.status = 404
+		resp.Errors = []string{fmt.Sprintf("no active game with id %q", gameId)}
+		return
+	}
+
+	// The game is marked as deleted before it's removed from storage, so a turn that was waiting
+	// on the lock can't save it again afterwards.
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	game.deleted = true
+	if game.clock != nil {
+		game.stopClock()
+	}
+	if err := store.Delete(gameId); err != nil {
+		game.deleted = false
+		game.resetClock()
+		resp.status = 500
+		resp.Errors = []string{fmt.Sprintf("failed to delete game: %v", err)}
+		return
+	}
+	delete(activeGames, gameId)
+	game.closeEvents()
+}
+
+func initializeLobbyRoutes(router *mux.Router) {
+	router.Methods("GET").Path("/games").HandlerFunc(listGames)
+	router.Methods("POST").Path("/games").HandlerFunc(createGame)
+	router.Methods("POST").Path("/games/{gameId}/join").HandlerFunc(joinGame)
+	router.Methods("DELETE").Path("/games/{gameId}").HandlerFunc(deleteGame)
+	router.Methods("GET").Path("/boards").HandlerFunc(listBoards)
+}
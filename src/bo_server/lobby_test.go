@@ -0,0 +1,397 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"boardInfo"
+	"gameState"
+)
+
+func newLobbyRouter() *mux.Router {
+	router := mux.NewRouter()
+	initializeLobbyRoutes(router)
+	initializeGameRoutes(router)
+	return router
+}
+
+func decodeSummary(t *testing.T, resp *jsonResponse) gameSummary {
+	var result gameSummary
+	if resp == nil {
+		t.Fatal("no response to decode game summary from")
+	} else if buf, err := json.Marshal(resp.Result); err != nil {
+		t.Fatalf("failed to re-encode response: %v", err)
+	} else if err = json.Unmarshal(buf, &result); err != nil {
+		t.Fatalf("failed to decode game summary: %v", err)
+	}
+	return result
+}
+
+// TestLobbyCreateGame checks the validation on creating games and makes sure games that have all
+// their seats filled start immediately.
+func TestLobbyCreateGame(t *testing.T) {
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_create")
+
+	badBodies := []map[string]interface{}{
+		{"id": "lobby_create"},
+		{"id": "bad id", "players": []string{"1st"}},
+		{"id": "games", "players": []string{"1st"}},
+		{"id": "lobby_create", "players": []string{"1st", "1st"}},
+	}
+	for _, body := range badBodies {
+		if status, _ := doRequest(t, router, "POST", "/games", body); status != 400 {
+			t.Errorf("creating game with %v returned status %d, expected 400", body, status)
+		}
+	}
+
+	body := map[string]interface{}{"id": "lobby_create", "players": []string{"1st", "2nd"}}
+	status, resp := doRequest(t, router, "POST", "/games", body)
+	if status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+	if summary := decodeSummary(t, resp); summary.Open || summary.Round != 1 {
+		t.Errorf("game with all seats filled didn't start: %+v", summary)
+	}
+	if status, _ := doRequest(t, router, "POST", "/games", body); status != 400 {
+		t.Errorf("creating duplicate game returned status %d, expected 400", status)
+	}
+	if status, _ := doRequest(t, router, "GET", "/lobby_create/state", nil); status != 200 {
+		t.Errorf("getting state of new game returned status %d", status)
+	}
+
+	// Make sure games created without an ID get one.
+	status, resp = doRequest(t, router, "POST", "/games", map[string]interface{}{"seats": 3})
+	if status != 201 {
+		t.Fatalf("creating game without ID returned status %d: %+v", status, resp)
+	}
+	if summary := decodeSummary(t, resp); summary.Id == "" {
+		t.Error("game created without ID was not assigned one")
+	} else {
+		removeTestGame(summary.Id)
+	}
+}
+
+// TestLobbyOpenSeats checks to make sure a game with open seats can't be played until enough
+// players have joined, but that players can take their seats and watch the game while they wait.
+func TestLobbyOpenSeats(t *testing.T) {
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_seats")
+
+	body := map[string]interface{}{"id": "lobby_seats", "players": []string{"1st"}, "seats": 3}
+	if status, resp := doRequest(t, router, "POST", "/games", body); status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+
+	join := func(name string) (int, gameSummary) {
+		body := map[string]string{"player_name": name}
+		status, resp := doRequest(t, router, "POST", "/games/lobby_seats/join", body)
+		if status != 200 {
+			return status, gameSummary{}
+		}
+		return status, decodeSummary(t, resp)
+	}
+	waitingFor := func() int {
+		status, resp := doRequest(t, router, "GET", "/lobby_seats/state", nil)
+		if status != 200 {
+			t.Fatalf("getting state of open game returned status %d: %+v", status, resp)
+		}
+		var state struct {
+			Waiting int `json:"waiting_for"`
+		}
+		if buf, err := json.Marshal(resp.Result); err != nil {
+			t.Fatalf("failed to re-encode response: %v", err)
+		} else if err = json.Unmarshal(buf, &state); err != nil {
+			t.Fatalf("failed to decode game state: %v", err)
+		}
+		return state.Waiting
+	}
+
+	mapLock.RLock()
+	token := seatToken(activeGames["lobby_seats"], "1st")
+	mapLock.RUnlock()
+	request := httptest.NewRequest("GET", "/lobby_seats/seat?token="+token, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusSeeOther {
+		t.Errorf("taking seat in open game returned status %d, expected %d", recorder.Code,
+			http.StatusSeeOther)
+	}
+
+	if waiting := waitingFor(); waiting != 2 {
+		t.Errorf("open game is waiting for %d players, expected 2", waiting)
+	}
+	for _, url := range []string{"/lobby_seats/legal_moves", "/lobby_seats/undo"} {
+		if status, _ := doRequest(t, router, "GET", url, nil); status != 409 {
+			t.Errorf("getting %s of open game returned status %d, expected 409", url, status)
+		}
+	}
+	if status, _ := join("1st"); status != 409 {
+		t.Errorf("joining with duplicate name returned status %d, expected 409", status)
+	}
+	if status, summary := join("2nd"); status != 200 {
+		t.Errorf("joining open game returned status %d", status)
+	} else if !summary.Open {
+		t.Errorf("game started before all seats were filled: %+v", summary)
+	}
+	if waiting := waitingFor(); waiting != 1 {
+		t.Errorf("open game is waiting for %d players, expected 1", waiting)
+	}
+
+	expected := []string{"1st", "2nd", "3rd"}
+	if status, summary := join("3rd"); status != 200 {
+		t.Errorf("joining open game returned status %d", status)
+	} else if summary.Open {
+		t.Errorf("game didn't start after all seats were filled: %+v", summary)
+	} else if !reflect.DeepEqual(summary.Players, expected) {
+		t.Errorf("game players %v don't match %v", summary.Players, expected)
+	}
+	if status, _ := doRequest(t, router, "GET", "/lobby_seats/state", nil); status != 200 {
+		t.Errorf("getting state of started game returned status %d", status)
+	}
+	if status, _ := join("4th"); status != 409 {
+		t.Errorf("joining started game returned status %d, expected 409", status)
+	}
+}
+
+// TestLobbyListDelete checks to make sure games show up in the list until they are deleted.
+func TestLobbyListDelete(t *testing.T) {
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_list")
+
+	body := map[string]interface{}{"id": "lobby_list", "players": []string{"1st", "2nd"}}
+	if status, resp := doRequest(t, router, "POST", "/games", body); status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+
+	listed := func() bool {
+		status, resp := doRequest(t, router, "GET", "/games", nil)
+		if status != 200 {
+			t.Fatalf("listing games returned status %d: %+v", status, resp)
+		}
+		var summaries []gameSummary
+		if buf, err := json.Marshal(resp.Result); err != nil {
+			t.Fatalf("failed to re-encode response: %v", err)
+		} else if err = json.Unmarshal(buf, &summaries); err != nil {
+			t.Fatalf("failed to decode game list: %v", err)
+		}
+		for _, summary := range summaries {
+			if summary.Id == "lobby_list" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !listed() {
+		t.Error("new game not in the list of games")
+	}
+	mapLock.RLock()
+	game := activeGames["lobby_list"]
+	mapLock.RUnlock()
+	if status, _ := doRequest(t, router, "DELETE", "/games/lobby_list", nil); status != 200 {
+		t.Errorf("deleting game returned status %d", status)
+	}
+	if listed() {
+		t.Error("deleted game still in the list of games")
+	}
+	if status, _ := doRequest(t, router, "DELETE", "/games/lobby_list", nil); status != 404 {
+		t.Errorf("deleting missing game returned status %d, expected 404", status)
+	}
+
+	// A turn that was waiting on the lock when the game was deleted can't save it again.
+	game.lock.Lock()
+	var resp jsonResponse
+	game.save(&resp)
+	game.lock.Unlock()
+	if games, err := store.LoadAll(); err != nil {
+		t.Errorf("failed to load games: %v", err)
+	} else if games["lobby_list"] != nil {
+		t.Error("deleted game still in the store")
+	}
+}
+
+// TestLobbyEndConditions checks to make sure games can be created with their own end conditions,
+// and that the lobby shows when they are over.
+func TestLobbyEndConditions(t *testing.T) {
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_end")
+
+	body := map[string]interface{}{
+		"id":             "lobby_end",
+		"players":        []string{"1st", "2nd"},
+		"end_conditions": map[string]interface{}{"max_rounds": 1},
+	}
+	status, resp := doRequest(t, router, "POST", "/games", body)
+	if status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+	tokens := resp.Result.(map[string]interface{})["seat_tokens"].(map[string]interface{})
+
+	mapLock.RLock()
+	game := activeGames["lobby_end"]
+	mapLock.RUnlock()
+	for range tokens {
+		token := tokens[game.game.TurnManager.Current()].(string)
+		pass := gameState.MarketTurn{}
+		status, resp := doSeatRequest(t, router, token, "POST", "/lobby_end/market_turn", pass)
+		if status != 200 {
+			t.Fatalf("pass returned status %d: %+v", status, resp)
+		}
+	}
+
+	if summary := game.summary(); !summary.Finished || summary.Turn != "" {
+		t.Errorf("game isn't over after the last round: %+v", summary)
+	}
+	for _, token := range tokens {
+		pass := gameState.MarketTurn{}
+		url := "/lobby_end/market_turn"
+		if status, _ := doSeatRequest(t, router, token.(string), "POST", url, pass); status != 409 {
+			t.Errorf("turn after the game is over returned status %d, expected 409", status)
+		}
+	}
+}
+
+// TestLobbyGameOptions checks to make sure games can be created with their own rules, that any
+// rules left out keep their standard values, and that rules that can't be played are rejected.
+func TestLobbyGameOptions(t *testing.T) {
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_options")
+
+	body := map[string]interface{}{
+		"id":      "lobby_options",
+		"players": []string{"1st", "2nd"},
+		"seats":   3,
+		"options": map[string]interface{}{"business_phases": 0},
+	}
+	if status, _ := doRequest(t, router, "POST", "/games", body); status != 400 {
+		t.Errorf("creating game with bad options returned status %d, expected 400", status)
+	}
+
+	body["options"] = map[string]interface{}{
+		"starting_cash": 3000,
+		"companies":     []string{"Erie", "Wabash"},
+	}
+	if status, resp := doRequest(t, router, "POST", "/games", body); status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+	join := map[string]string{"player_name": "3rd"}
+	status, resp := doRequest(t, router, "POST", "/games/lobby_options/join", join)
+	if status != 200 {
+		t.Fatalf("joining game returned status %d: %+v", status, resp)
+	}
+
+	mapLock.RLock()
+	game := activeGames["lobby_options"].game
+	mapLock.RUnlock()
+	expected := gameState.DefaultGameOptions
+	expected.StartingCash, expected.Companies = 3000, []string{"Erie", "Wabash"}
+	if !reflect.DeepEqual(game.Options, expected) {
+		t.Errorf("game has options %+v, expected %+v", game.Options, expected)
+	}
+	if cash := game.Players["3rd"].Cash; cash != 1000 || len(game.Companies) != 2 {
+		t.Errorf("game has %d companies and players start with $%d, expected 2 and $1000",
+			len(game.Companies), cash)
+	}
+}
+
+// TestLobbyBoards checks to make sure games can be created on boards loaded from a directory, that
+// the lobby shows which board each game uses, and that the choice is saved with the game.
+func TestLobbyBoards(t *testing.T) {
+	const boardName = "lobby_boards"
+	if boardInfo.FindBoard(boardName) == nil {
+		dir := t.TempDir()
+		board := `{"hexes": {
+			"A1": {"build_cost": 10, "city": {"name": "West", "revenue": [10, 20, 30, 40, 50, 60],
+				"starting": "Erie"}},
+			"A3": {"build_cost": 20, "coal": true},
+			"A5": {"build_cost": 10, "city": {"name": "East", "revenue": [60, 50, 40, 30, 20, 10],
+				"starting": "Wabash"}}
+		}}`
+		path := filepath.Join(dir, boardName+".json")
+		if err := ioutil.WriteFile(path, []byte(board), 0644); err != nil {
+			t.Fatalf("failed to write board: %v", err)
+		} else if err := loadBoards(dir); err != nil {
+			t.Fatalf("failed to load boards: %v", err)
+		}
+	}
+	router := newLobbyRouter()
+	defer removeTestGame("lobby_boards")
+
+	status, resp := doRequest(t, router, "GET", "/boards", nil)
+	if status != 200 {
+		t.Fatalf("listing boards returned status %d: %+v", status, resp)
+	} else if names, _ := resp.Result.([]interface{}); len(names) < 2 {
+		t.Errorf("boards %v are missing the loaded board or the standard board", resp.Result)
+	}
+
+	body := map[string]interface{}{
+		"id":      "lobby_boards",
+		"players": []string{"1st", "2nd"},
+		"options": map[string]interface{}{"board": "not a board"},
+	}
+	if status, _ := doRequest(t, router, "POST", "/games", body); status != 400 {
+		t.Errorf("creating game on an unknown board returned status %d, expected 400", status)
+	}
+	body["options"] = map[string]interface{}{"board": boardName}
+	if status, _ := doRequest(t, router, "POST", "/games", body); status != 400 {
+		t.Errorf("creating game without starting locations returned status %d, expected 400",
+			status)
+	}
+
+	body["options"] = map[string]interface{}{
+		"board":     boardName,
+		"companies": []string{"Erie", "Wabash"},
+	}
+	status, resp = doRequest(t, router, "POST", "/games", body)
+	if status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	} else if summary := decodeSummary(t, resp); summary.Board != boardName {
+		t.Errorf("game is listed on board %q, expected %q", summary.Board, boardName)
+	}
+
+	status, resp = doRequest(t, router, "GET", "/lobby_boards/board", nil)
+	if status != 200 {
+		t.Fatalf("getting the board returned status %d: %+v", status, resp)
+	} else if hexes, _ := resp.Result.(map[string]interface{}); len(hexes) != 3 {
+		t.Errorf("board has %d hexes, expected the 3 on the loaded board", len(hexes))
+	}
+
+	if games, err := store.LoadAll(); err != nil {
+		t.Fatalf("failed to load games: %v", err)
+	} else if record := games["lobby_boards"]; record == nil {
+		t.Error("game wasn't saved")
+	} else if record.Options.Board != boardName ||
+		record.Game.Board() != boardInfo.FindBoard(boardName) {
+		t.Errorf("saved game is on board %q, expected %q", record.Options.Board, boardName)
+	}
+}
+
+// TestBoardInfo checks to make sure the old board route still describes the default game's board,
+// and that it reports when there is no default game.
+func TestBoardInfo(t *testing.T) {
+	boardInfoBody := func() (int, string) {
+		recorder := httptest.NewRecorder()
+		getBoardInfo(recorder, httptest.NewRequest("GET", "/board_info", nil))
+		return recorder.Code, recorder.Body.String()
+	}
+	if status, _ := boardInfoBody(); status != 404 {
+		t.Errorf("board info without a default game returned status %d, expected 404", status)
+	}
+
+	handler, _ := newTestRouter(t, "game", []string{"1st", "2nd"})
+	defer removeTestGame("game")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/game/board", nil))
+	if status, body := boardInfoBody(); status != 200 || body != recorder.Body.String() {
+		t.Errorf("board info returned status %d with a different board than the default game",
+			status)
+	}
+}
@@ -2,22 +2,27 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 
 	"boardInfo"
+	"gameState"
 )
 
 type jsonResponse struct {
-	status int         `json:"-"`
-	Errors []string    `json:"errors"`
-	Result interface{} `json:"result"`
+	status       int                    `json:"-"`
+	Errors       []string               `json:"errors"`
+	ErrorDetails []*gameState.RuleError `json:"error_details,omitempty"`
+	Result       interface{}            `json:"result"`
 }
 
 func readBody(data interface{}, request *http.Request) error {
@@ -53,16 +58,61 @@ func convertErrors(errs []error) []string {
 	return result
 }
 
-func getboardInfo(writer http.ResponseWriter, request *http.Request) {
-	resp := jsonResponse{}
-	defer writeJson(&resp, writer)
+// setRuleErrors fills in the response for a turn the game rejected. The details include the code
+// for each broken rule. Turns taken at the wrong time conflict with the current state of the game,
+// while the rest could never be taken as they are. Any error that didn't come from the rules means
+// the server itself failed.
+func (resp *jsonResponse) setRuleErrors(errs []error) {
+	resp.status = 422
+	resp.Errors = convertErrors(errs)
+	for _, err := range errs {
+		var ruleErr *gameState.RuleError
+		if !errors.As(err, &ruleErr) {
+			resp.status = 500
+			continue
+		}
+		resp.ErrorDetails = append(resp.ErrorDetails, ruleErr)
+		if ruleErr.Code.WrongTime() && resp.status != 500 {
+			resp.status = 409
+		}
+	}
+}
 
-	if buf, err := boardInfo.JsonMap(); err != nil {
-		resp.status = 500
-		resp.Errors = []string{err.Error()}
-	} else {
-		resp.Result = (*json.RawMessage)(&buf)
+// loadBoards registers every board definition in the directory, named after its file without the
+// extension, so games can be created on them.
+func loadBoards(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		board, err := boardInfo.LoadBoard(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if err := boardInfo.RegisterBoard(name, board); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// getBoardInfo describes the board of the default game. It's kept for clients written before each
+// game had its own board, which should use /{gameId}/board instead.
+func getBoardInfo(writer http.ResponseWriter, request *http.Request) {
+	mapLock.RLock()
+	game := activeGames["game"]
+	mapLock.RUnlock()
+	if game == nil {
+		writeJson(&jsonResponse{status: 404, Errors: []string{"no default game"}}, writer)
+		return
 	}
+	game.getBoard(writer, request)
 }
 
 func getTrainCosts(writer http.ResponseWriter, request *http.Request) {
@@ -71,20 +121,61 @@ func getTrainCosts(writer http.ResponseWriter, request *http.Request) {
 
 func main() {
 	port := flag.Int("port", 8000, "the port the web server will listen on")
+	storePath := flag.String("store", "games.gob", "the file games are saved to (empty for none)")
+	boardsPath := flag.String("boards", "", "a directory of extra board definitions to load")
+	undoConsent := flag.Bool("undo_consent", false, "require every player to approve an undo")
+	maxRounds := flag.Int("max_rounds", 0, "rounds played before the game ends (0 for no limit)")
+	var clock clockSettings
+	flag.IntVar(&clock.TurnSeconds, "turn_seconds", 0, "seconds each turn gets (0 for no limit)")
+	flag.IntVar(&clock.BankSeconds, "bank_seconds", 0,
+		"extra seconds each player can spend across all their turns")
+	flag.BoolVar(&debugInvariants, "check_invariants", false,
+		"log any broken game invariants after every action")
 	flag.Parse()
 
 	rand.Seed(int64(time.Now().Nanosecond()))
-	if flag.NArg() > 0 {
-		addNewGame("game", flag.Args())
+	if *boardsPath != "" {
+		if err := loadBoards(*boardsPath); err != nil {
+			panic(err)
+		}
+	}
+	if *storePath != "" {
+		if fileStore, err := newFileStore(*storePath); err != nil {
+			panic(err)
+		} else {
+			store = fileStore
+		}
+	}
+	if err := loadGames(); err != nil {
+		panic(err)
+	}
+
+	// Only create the default game if it didn't survive from the last time the server ran.
+	if _, exists := activeGames["game"]; exists {
+		fmt.Println("resuming saved game")
 	} else {
-		addNewGame("game", []string{"1st", "2nd", "3rd", "4th"})
+		playerNames := flag.Args()
+		if len(playerNames) == 0 {
+			playerNames = []string{"1st", "2nd", "3rd", "4th"}
+		}
+		endConditions := gameState.DefaultEndConditions
+		endConditions.MaxRounds = *maxRounds
+		_, _, err := addNewGame("game", playerNames, 0, *undoConsent, endConditions,
+			gameState.DefaultGameOptions, clock)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for token, name := range activeGames["game"].tokens {
+		fmt.Printf("%s: http://localhost:%d/game/seat?token=%s\n", name, *port, token)
 	}
 
 	router := mux.NewRouter()
+	initializeLobbyRoutes(router)
 	initializeGameRoutes(router)
 
 	static := router.Methods("GET").Subrouter()
-	static.HandleFunc("/board_info", getboardInfo)
+	static.HandleFunc("/board_info", getBoardInfo)
 	static.HandleFunc("/train_costs", getTrainCosts)
 	static.PathPrefix("/").Handler(http.StripPrefix("/", http.FileServer(AssetFS{})))
 
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// seatCookie is the name of the cookie that holds a player's seat token. The cookie's path is
+// limited to the game the seat belongs to, so the same name can be used for every game.
+const seatCookie = "seat_token"
+
+func newSeatToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func requestToken(request *http.Request) string {
+	if auth := request.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	if cookie, err := request.Cookie(seatCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func (r *gameRouter) setSeatCookie(writer http.ResponseWriter, token string) {
+	http.SetCookie(writer, &http.Cookie{
+		Name:     seatCookie,
+		Value:    token,
+		Path:     "/" + r.id,
+		HttpOnly: true,
+	})
+}
+
+// seatPlayer returns the name of the player whose seat token was provided with the request. It
+// returns an empty string for spectators, who didn't provide a valid token. It must be called with
+// the lock held.
+func (r *gameRouter) seatPlayer(request *http.Request) string {
+	if token := requestToken(request); token != "" {
+		return r.tokens[token]
+	}
+	return ""
+}
+
+// takeSeat lets players use a link with their seat token to have the token saved as a cookie,
+// which the browser will then automatically include on all of the requests for the game.
+func (r *gameRouter) takeSeat(writer http.ResponseWriter, request *http.Request) {
+	token := request.URL.Query().Get("token")
+
+	r.lock.RLock()
+	_, valid := r.tokens[token]
+	r.lock.RUnlock()
+
+	if !valid {
+		writeJson(&jsonResponse{status: 401, Errors: []string{"invalid seat token"}}, writer)
+		return
+	}
+	r.setSeatCookie(writer, token)
+	http.Redirect(writer, request, "/", http.StatusSeeOther)
+}
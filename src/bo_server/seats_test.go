@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"gameState"
+)
+
+// TestSeatTokens checks to make sure turns can only be taken with the seat token of the player
+// whose turn it is, and that spectators without a token can still see the game.
+func TestSeatTokens(t *testing.T) {
+	const url = "/seat_tokens/market_turn"
+	handler, router := newTestRouter(t, "seat_tokens", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("seat_tokens")
+
+	for _, route := range []string{"state", "players", "companies"} {
+		if status, _ := doRequest(t, handler, "GET", "/seat_tokens/"+route, nil); status != 200 {
+			t.Errorf("spectator request for %s returned status %d", route, status)
+		}
+	}
+
+	// The player name in the body was how players used to be identified, so make sure it no
+	// longer has any effect.
+	current := router.game.TurnManager.Current()
+	body := map[string]string{"player_name": current}
+	if status, _ := doRequest(t, handler, "POST", url, body); status != 401 {
+		t.Errorf("market turn without token returned status %d, expected 401", status)
+	}
+	if status, _ := doSeatRequest(t, handler, "bad token", "POST", url, body); status != 401 {
+		t.Errorf("market turn with invalid token returned status %d, expected 401", status)
+	}
+	for name := range router.game.Players {
+		if name == current {
+			continue
+		}
+		token := seatToken(router, name)
+		if status, _ := doSeatRequest(t, handler, token, "POST", url, body); status != 409 {
+			t.Errorf("%s's market turn on %s's turn returned status %d", name, current, status)
+		}
+	}
+	if router.game.TurnManager.Number != 0 {
+		t.Fatalf("turn advanced to %d without the current player", router.game.TurnManager.Number)
+	}
+
+	token := seatToken(router, current)
+	pass := gameState.MarketTurn{}
+	if status, resp := doSeatRequest(t, handler, token, "POST", url, pass); status != 200 {
+		t.Errorf("%s's market turn returned status %d: %+v", current, status, resp)
+	}
+	if router.game.TurnManager.Number != 1 {
+		t.Errorf("turn number is %d after one pass", router.game.TurnManager.Number)
+	}
+}
+
+// TestSeatCookie checks to make sure players can use the seat link to save their token in a
+// cookie and then take their turns using just the cookie.
+func TestSeatCookie(t *testing.T) {
+	handler, router := newTestRouter(t, "seat_cookie", []string{"1st", "2nd"})
+	defer removeTestGame("seat_cookie")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/seat_cookie/seat?token=bad", nil))
+	if recorder.Code != 401 {
+		t.Errorf("seat link with invalid token returned status %d, expected 401", recorder.Code)
+	}
+
+	current := router.game.TurnManager.Current()
+	link := "/seat_cookie/seat?token=" + seatToken(router, current)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", link, nil))
+	cookies := recorder.Result().Cookies()
+	if recorder.Code != 303 {
+		t.Fatalf("seat link returned status %d, expected 303", recorder.Code)
+	} else if len(cookies) != 1 || cookies[0].Name != seatCookie {
+		t.Fatalf("seat link set cookies %v", cookies)
+	}
+
+	request := httptest.NewRequest("POST", "/seat_cookie/market_turn", bytes.NewBufferString("{}"))
+	request.AddCookie(cookies[0])
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != 200 {
+		t.Errorf("market turn with seat cookie returned status %d: %s", recorder.Code, recorder.Body)
+	}
+}
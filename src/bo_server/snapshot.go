@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gameState"
+)
+
+// The gameSnapshot struct holds every view of the game taken at a single version, so clients never
+// see the state from before a turn mixed with the players or companies from after it.
+type gameSnapshot struct {
+	Version   int                           `json:"version"`
+	State     stateView                     `json:"state"`
+	Players   map[string]*gameState.Player  `json:"players"`
+	Companies map[string]*gameState.Company `json:"companies"`
+}
+
+// snapshot takes a snapshot of the game. It must be called with the lock held, and the snapshot
+// must be encoded before the lock is released since it shares the game's players and companies.
+// Games that are still waiting for players have no players or companies yet.
+func (r *gameRouter) snapshot() gameSnapshot {
+	if r.game == nil {
+		return gameSnapshot{
+			State:     r.stateView(),
+			Players:   map[string]*gameState.Player{},
+			Companies: map[string]*gameState.Company{},
+		}
+	}
+	return gameSnapshot{
+		Version:   r.game.Version,
+		State:     r.stateView(),
+		Players:   r.game.Players,
+		Companies: r.game.Companies,
+	}
+}
+
+// getSnapshot returns the snapshot of the game with its version as the ETag. Clients that send
+// the ETag they already have in If-None-Match get an empty 304 response until the game changes.
+func (r *gameRouter) getSnapshot(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	etag := r.etag()
+	writer.Header().Set("ETag", etag)
+	writer.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(request.Header.Get("If-None-Match"), etag, true) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJson(&jsonResponse{Result: r.snapshot()}, writer)
+}
+
+// etag returns the ETag for the game's current version. Games that are still waiting for players
+// use the number of open seats instead, so the ETag changes as players join. It must be called
+// with the lock held.
+func (r *gameRouter) etag() string {
+	if r.game == nil {
+		return fmt.Sprintf(`"waiting-%d"`, r.seats-len(r.players))
+	}
+	return fmt.Sprintf(`"%d"`, r.game.Version)
+}
+
+// etagMatches checks if the ETag is one of those listed in an If-None-Match or If-Match header.
+// Weak ETags only match if weak is set, since If-Match always uses the strong comparison.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// The versionConflict struct is the result for requests that expected the game to be at a version
+// it has already moved past.
+type versionConflict struct {
+	Version int `json:"version"`
+}
+
+// checkVersion makes sure the game is still at the version the request expects, if it gave one
+// in the If-Match header or the expected_version parameter. If the game has moved on the response
+// is filled in with a 409 and the current version so the client can refresh before trying again.
+// It must be called with the lock held.
+func (r *gameRouter) checkVersion(writer http.ResponseWriter, request *http.Request,
+	resp *jsonResponse) bool {
+	current := true
+	if param := request.URL.Query().Get("expected_version"); param != "" {
+		expected, err := strconv.Atoi(param)
+		if err != nil {
+			resp.status = 400
+			resp.Errors = []string{fmt.Sprintf("invalid expected version %q", param)}
+			return false
+		}
+		current = expected == r.game.Version
+	}
+	if header := request.Header.Get("If-Match"); header != "" {
+		current = current && etagMatches(header, r.etag(), false)
+	}
+	if current {
+		return true
+	}
+
+	writer.Header().Set("ETag", r.etag())
+	resp.status = 409
+	resp.Errors = []string{fmt.Sprintf("the game has changed, it is now at version %d",
+		r.game.Version)}
+	resp.Result = versionConflict{Version: r.game.Version}
+	return false
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gameState"
+)
+
+// getSnapshot requests the game's snapshot with the ETag provided in If-None-Match, returning the
+// response so the test can check the headers as well as the body.
+func getSnapshot(handler http.Handler, gameId, etag string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", "/"+gameId+"/snapshot", nil)
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// TestSnapshot checks to make sure the snapshot includes every view of the game at its current
+// version, and that clients that already have the current version get a 304.
+func TestSnapshot(t *testing.T) {
+	handler, router := newTestRouter(t, "snapshot", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("snapshot")
+
+	recorder := getSnapshot(handler, "snapshot", "")
+	etag := recorder.Header().Get("ETag")
+	if recorder.Code != 200 || etag != `"0"` {
+		t.Fatalf("snapshot returned status %d with ETag %s: %s", recorder.Code, etag,
+			recorder.Body)
+	}
+	var resp struct {
+		Result struct {
+			Version   int                        `json:"version"`
+			State     map[string]interface{}     `json:"state"`
+			Players   map[string]json.RawMessage `json:"players"`
+			Companies map[string]json.RawMessage `json:"companies"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("snapshot returned invalid JSON: %v", err)
+	}
+	snapshot := resp.Result
+	if snapshot.Version != 0 || snapshot.State["turn"] != router.game.TurnManager.Current() ||
+		len(snapshot.Players) != 3 || len(snapshot.Companies) != len(router.game.Companies) {
+		t.Errorf("snapshot is missing part of the game: %+v", snapshot)
+	}
+
+	for _, match := range []string{etag, "W/" + etag, `"7", ` + etag, "*"} {
+		if recorder := getSnapshot(handler, "snapshot", match); recorder.Code != 304 {
+			t.Errorf("unchanged snapshot with If-None-Match %s returned status %d", match,
+				recorder.Code)
+		} else if recorder.Body.Len() > 0 {
+			t.Errorf("unchanged snapshot returned a body: %s", recorder.Body)
+		}
+	}
+
+	passTurn(t, handler, router)
+	recorder = getSnapshot(handler, "snapshot", etag)
+	if recorder.Code != 200 || recorder.Header().Get("ETag") != `"1"` {
+		t.Errorf("snapshot after a turn returned status %d with ETag %s", recorder.Code,
+			recorder.Header().Get("ETag"))
+	}
+
+	// Undoing the turn returns the game to its earlier state, but clients still need to see that
+	// it changed.
+	token := seatToken(router, router.game.Log[0].Player)
+	status, undoResp := doSeatRequest(t, handler, token, "POST", "/snapshot/undo", nil)
+	if status != 200 {
+		t.Fatalf("undo returned status %d: %+v", status, undoResp)
+	}
+	for _, match := range []string{etag, `"1"`} {
+		if recorder := getSnapshot(handler, "snapshot", match); recorder.Code != 200 {
+			t.Errorf("snapshot after undo with If-None-Match %s returned status %d", match,
+				recorder.Code)
+		}
+	}
+}
+
+// TestExpectedVersion checks to make sure turns that expect the game to be at an earlier version
+// are rejected with the current version, whether the version is a parameter or in If-Match.
+func TestExpectedVersion(t *testing.T) {
+	handler, router := newTestRouter(t, "expected_version", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("expected_version")
+
+	pass := func(query, ifMatch string) (*httptest.ResponseRecorder, *jsonResponse) {
+		var body bytes.Buffer
+		json.NewEncoder(&body).Encode(gameState.MarketTurn{})
+		request := httptest.NewRequest("POST", "/expected_version/market_turn"+query, &body)
+		token := seatToken(router, router.game.TurnManager.Current())
+		request.Header.Set("Authorization", "Bearer "+token)
+		if ifMatch != "" {
+			request.Header.Set("If-Match", ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		var resp jsonResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("turn returned invalid JSON: %v", err)
+		}
+		return recorder, &resp
+	}
+
+	if recorder, resp := pass("?expected_version=0", ""); recorder.Code != 200 {
+		t.Fatalf("turn at the expected version returned status %d: %+v", recorder.Code, resp)
+	}
+	if recorder, resp := pass("", `"1"`); recorder.Code != 200 {
+		t.Fatalf("turn with a matching If-Match returned status %d: %+v", recorder.Code, resp)
+	}
+
+	tests := []struct {
+		query, ifMatch string
+		status         int
+	}{
+		{query: "?expected_version=1", status: 409},
+		{ifMatch: `"1"`, status: 409},
+		{ifMatch: `W/"2"`, status: 409},
+		{query: "?expected_version=2", ifMatch: `"1"`, status: 409},
+		{query: "?expected_version=latest", status: 400},
+	}
+	for _, test := range tests {
+		recorder, resp := pass(test.query, test.ifMatch)
+		if recorder.Code != test.status {
+			t.Errorf("turn with %q and If-Match %s returned status %d, expected %d", test.query,
+				test.ifMatch, recorder.Code, test.status)
+		} else if test.status != 409 {
+			continue
+		}
+
+		result, _ := resp.Result.(map[string]interface{})
+		if result["version"] != float64(2) || recorder.Header().Get("ETag") != `"2"` {
+			t.Errorf("conflicting turn returned %+v with ETag %s", resp,
+				recorder.Header().Get("ETag"))
+		}
+	}
+	if len(router.game.Log) != 2 {
+		t.Errorf("game has %d actions, rejected turns were taken", len(router.game.Log))
+	}
+}
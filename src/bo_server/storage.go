@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gameState"
+)
+
+// The gameStore interface is what allows games to survive the server restarting. Every game is
+// written through to the store after it is created and after every successful action.
+type gameStore interface {
+	Save(gameId string, record *gameRecord) error
+	Delete(gameId string) error
+	LoadAll() (map[string]*gameRecord, error)
+}
+
+// gameRecord holds everything the server needs to restore a game. The game will be nil if it is
+// still waiting for players to fill its open seats. The tokens map the secret seat tokens to the
+// names of the players they belong to, and the bots map the players controlled by the computer
+// to the names of their strategies.
+type gameRecord struct {
+	Seats   int
+	Players []string
+	Tokens  map[string]string
+	Game    *gameState.Game
+	Bots    map[string]string
+
+	UndoConsent   bool
+	EndConditions gameState.EndConditions
+	Options       gameState.GameOptions
+	Clock         *turnClock
+}
+
+// store is where all of the active games are saved. It defaults to only keeping the games in
+// memory, which is what the tests rely on, and main replaces it if the user wants persistence.
+var store gameStore = newMemoryStore()
+
+func encodeRecord(record *gameRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeRecords(encoded map[string][]byte) (map[string]*gameRecord, error) {
+	result := make(map[string]*gameRecord, len(encoded))
+	for gameId, buf := range encoded {
+		record := new(gameRecord)
+		if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(record); err != nil {
+			return nil, fmt.Errorf("failed to decode game %q: %v", gameId, err)
+		}
+		result[gameId] = record
+	}
+	return result, nil
+}
+
+// memoryStore keeps encoded copies of the games so nothing saved can be changed afterwards by
+// continuing to play the game, matching the behavior of the file store.
+type memoryStore struct {
+	lock  sync.Mutex
+	games map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{games: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Save(gameId string, record *gameRecord) error {
+	buf, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.games[gameId] = buf
+	return nil
+}
+
+func (s *memoryStore) Delete(gameId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.games, gameId)
+	return nil
+}
+
+func (s *memoryStore) LoadAll() (map[string]*gameRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return decodeRecords(s.games)
+}
+
+// fileStore keeps all of the games in a single gob encoded file. Every save rewrites the whole
+// file, but it is first written to a temporary file that is then renamed to replace the old one
+// so a crash in the middle of a save can never leave behind a corrupted file.
+type fileStore struct {
+	lock  sync.Mutex
+	path  string
+	games map[string][]byte
+}
+
+func newFileStore(path string) (*fileStore, error) {
+	result := &fileStore{path: path, games: make(map[string][]byte)}
+
+	if file, err := os.Open(path); os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	} else {
+		defer file.Close()
+		if err = gob.NewDecoder(file).Decode(&result.games); err != nil {
+			return nil, fmt.Errorf("failed to read games from %s: %v", path, err)
+		}
+	}
+	return result, nil
+}
+
+func (s *fileStore) flush() error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err = gob.NewEncoder(tmpFile).Encode(s.games); err != nil {
+		tmpFile.Close()
+		return err
+	} else if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	} else if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), s.path)
+}
+
+func (s *fileStore) Save(gameId string, record *gameRecord) error {
+	buf, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.games[gameId] = buf
+	return s.flush()
+}
+
+func (s *fileStore) Delete(gameId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.games, gameId)
+	return s.flush()
+}
+
+func (s *fileStore) LoadAll() (map[string]*gameRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return decodeRecords(s.games)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gameState"
+)
+
+func testStore(t *testing.T, store gameStore, reopen func() gameStore) {
+	games := map[string]*gameRecord{
+		"first": &gameRecord{
+			Seats:   3,
+			Players: []string{"1st", "2nd", "3rd"},
+			Game:    gameState.NewGame([]string{"1st", "2nd", "3rd"}),
+		},
+		"second": &gameRecord{Seats: 4, Players: []string{"a", "b"}},
+	}
+	for gameId, record := range games {
+		if err := store.Save(gameId, record); err != nil {
+			t.Fatalf("failed to save game %q: %v", gameId, err)
+		}
+	}
+	if loaded, err := reopen().LoadAll(); err != nil {
+		t.Fatalf("failed to load games: %v", err)
+	} else if !reflect.DeepEqual(loaded, games) {
+		t.Errorf("loaded games don't match the saved games\n\n%+v\n\n%+v", loaded, games)
+	}
+
+	// Make sure changes made after the save don't affect the saved game until it's saved again.
+	game := games["first"].Game
+	playerName := game.TurnManager.Current()
+	if errs := game.PerformMarketTurn(playerName, gameState.MarketTurn{}); len(errs) > 0 {
+		t.Fatalf("failed to pass market turn: %v", errs)
+	}
+	if loaded, err := reopen().LoadAll(); err != nil {
+		t.Fatalf("failed to load games: %v", err)
+	} else if reflect.DeepEqual(loaded["first"], games["first"]) {
+		t.Error("saved game changed without being saved again")
+	}
+	if err := store.Save("first", games["first"]); err != nil {
+		t.Fatalf("failed to save game: %v", err)
+	}
+	if loaded, err := reopen().LoadAll(); err != nil {
+		t.Fatalf("failed to load games: %v", err)
+	} else if !reflect.DeepEqual(loaded["first"], games["first"]) {
+		t.Errorf("loaded game doesn't match the updated game\n\n%+v\n\n%+v",
+			loaded["first"].Game, game)
+	}
+
+	if err := store.Delete("second"); err != nil {
+		t.Fatalf("failed to delete game: %v", err)
+	}
+	if loaded, err := reopen().LoadAll(); err != nil {
+		t.Fatalf("failed to load games: %v", err)
+	} else if len(loaded) != 1 || loaded["second"] != nil {
+		t.Errorf("loaded games %v after deleting \"second\"", loaded)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := newMemoryStore()
+	testStore(t, store, func() gameStore { return store })
+}
+
+// TestFileStore checks to make sure the games saved in a file store can be read back from the
+// same file by a completely new store, which is what happens when the server restarts.
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bo_store")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "games.gob")
+	store, err := newFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to create file store: %v", err)
+	}
+	testStore(t, store, func() gameStore {
+		result, err := newFileStore(path)
+		if err != nil {
+			t.Fatalf("failed to reopen file store: %v", err)
+		}
+		return result
+	})
+
+	if files, err := ioutil.ReadDir(dir); err != nil {
+		t.Errorf("failed to read temporary directory: %v", err)
+	} else if len(files) != 1 {
+		t.Errorf("file store left behind extra files: %v", files)
+	}
+}
+
+// TestRouterWriteThrough checks to make sure the game router saves the game after every
+// successful action and doesn't save after failed ones.
+func TestRouterWriteThrough(t *testing.T) {
+	const url = "/write_through/market_turn"
+	pass := gameState.MarketTurn{}
+	handler, router := newTestRouter(t, "write_through", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("write_through")
+
+	loadSaved := func() *gameState.Game {
+		games, err := store.LoadAll()
+		if err != nil {
+			t.Fatalf("failed to load games: %v", err)
+		}
+		return games["write_through"].Game
+	}
+	if saved := loadSaved(); !reflect.DeepEqual(saved, router.game) {
+		t.Errorf("new game wasn't saved\n\n%+v\n\n%+v", saved, router.game)
+	}
+
+	current := router.game.TurnManager.Current()
+	for name := range router.game.Players {
+		if name != current {
+			token := seatToken(router, name)
+			if status, _ := doSeatRequest(t, handler, token, "POST", url, pass); status == 200 {
+				t.Errorf("%s passed on %s's turn", name, current)
+			}
+		}
+	}
+	if saved := loadSaved(); saved.TurnManager.Number != 0 {
+		t.Errorf("saved game turn number is %d after failed actions", saved.TurnManager.Number)
+	}
+
+	token := seatToken(router, current)
+	if status, resp := doSeatRequest(t, handler, token, "POST", url, pass); status != 200 {
+		t.Fatalf("%s failed to pass: %+v", current, resp)
+	}
+	if saved := loadSaved(); !reflect.DeepEqual(saved, router.game) {
+		t.Errorf("game wasn't saved after market turn\n\n%+v\n\n%+v", saved, router.game)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"bots"
+	"gameState"
+)
+
+// expiredRetryDelay is the least amount of time before the server tries again to take the turn
+// for a player who ran out of time, when it wasn't able to the first time.
+const expiredRetryDelay = time.Minute
+
+// The clockSettings struct holds the options for a game's turn clock, as given when the game is
+// created. Every turn gets the same amount of time, and once that runs out the player starts
+// spending their time bank. Every player gets the same bank unless it is set for them by name.
+type clockSettings struct {
+	TurnSeconds int            `json:"turn_seconds"`
+	BankSeconds int            `json:"bank_seconds"`
+	PlayerBanks map[string]int `json:"player_banks,omitempty"`
+}
+
+// The turnClock struct tracks how much time the player who needs to act has left. Time spent past
+// the end of a turn comes out of the player's bank, and once that is gone too the server takes
+// a default turn for them.
+//
+// The clock is saved with the game, but the current turn is restarted when the game is restored
+// so players never lose time to the server being down.
+type turnClock struct {
+	TurnTime    time.Duration
+	DefaultBank time.Duration
+	Banks       map[string]time.Duration
+
+	Player  string
+	Started time.Time
+}
+
+// newTurnClock creates the clock for a game, returning nil if the settings don't use one.
+func newTurnClock(settings clockSettings) (*turnClock, error) {
+	if settings.TurnSeconds < 0 || settings.BankSeconds < 0 {
+		return nil, fmt.Errorf("turn clock can't have negative times")
+	} else if settings.TurnSeconds == 0 && settings.BankSeconds == 0 &&
+		len(settings.PlayerBanks) == 0 {
+		return nil, nil
+	}
+
+	result := &turnClock{
+		TurnTime:    time.Duration(settings.TurnSeconds) * time.Second,
+		DefaultBank: time.Duration(settings.BankSeconds) * time.Second,
+		Banks:       make(map[string]time.Duration, len(settings.PlayerBanks)),
+	}
+	for player, seconds := range settings.PlayerBanks {
+		if seconds < 0 {
+			return nil, fmt.Errorf("%s can't have a negative time bank", player)
+		}
+		result.Banks[player] = time.Duration(seconds) * time.Second
+	}
+	return result, nil
+}
+
+// bank returns the time left in the player's bank, not counting the current turn.
+func (c *turnClock) bank(player string) time.Duration {
+	if bank, exists := c.Banks[player]; exists {
+		return bank
+	}
+	return c.DefaultBank
+}
+
+// overtime returns how much of the current player's bank they have used so far this turn.
+func (c *turnClock) overtime(now time.Time) time.Duration {
+	used := now.Sub(c.Started) - c.TurnTime
+	if used < 0 {
+		return 0
+	} else if bank := c.bank(c.Player); used > bank {
+		return bank
+	}
+	return used
+}
+
+func (c *turnClock) deadline() time.Time {
+	return c.Started.Add(c.TurnTime + c.bank(c.Player))
+}
+
+// The clockStatus struct is what clients are shown about the clock. The times left are in
+// seconds, and the banks already have the current turn's overtime taken out.
+type clockStatus struct {
+	Player   string             `json:"player"`
+	Deadline time.Time          `json:"deadline"`
+	TurnLeft float64            `json:"turn_seconds_left"`
+	Banks    map[string]float64 `json:"bank_seconds_left"`
+}
+
+func (c *turnClock) status(players []string, now time.Time) *clockStatus {
+	result := &clockStatus{
+		Player: c.Player,
+		Banks:  make(map[string]float64, len(players)),
+	}
+	for _, player := range players {
+		result.Banks[player] = c.bank(player).Seconds()
+	}
+	if c.Player != "" {
+		result.Deadline = c.deadline()
+		result.Banks[c.Player] = (c.bank(c.Player) - c.overtime(now)).Seconds()
+		if left := c.Started.Add(c.TurnTime).Sub(now); left > 0 {
+			result.TurnLeft = left.Seconds()
+		}
+	}
+	return result
+}
+
+// The stateView struct is the game's global state along with the whole turn order and its clock,
+// which is what clients are sent for the state of the game. Until the game starts it only says how
+// many more players it's waiting for.
+type stateView struct {
+	gameState.GlobalState
+	TurnOrder gameState.TurnOrder `json:"turn_order"`
+	Clock     *clockStatus        `json:"clock,omitempty"`
+	Waiting   int                 `json:"waiting_for,omitempty"`
+}
+
+func (r *gameRouter) stateView() stateView {
+	if r.game == nil {
+		return stateView{Waiting: r.seats - len(r.players)}
+	}
+	result := stateView{GlobalState: r.game.GlobalState, TurnOrder: r.game.TurnOrder()}
+	if r.clock != nil {
+		result.Clock = r.clock.status(r.players, time.Now())
+	}
+	return result
+}
+
+// resetClock charges the player who just acted for any time they spent past the end of their
+// turn, then starts the clock for whoever needs to act next. It must be called with the lock held
+// after every action.
+func (r *gameRouter) resetClock() {
+	if r.clock == nil {
+		return
+	}
+	now := time.Now()
+	if r.clock.Banks == nil {
+		r.clock.Banks = make(map[string]time.Duration)
+	}
+	if r.clock.Player != "" {
+		r.clock.Banks[r.clock.Player] = r.clock.bank(r.clock.Player) - r.clock.overtime(now)
+	}
+	r.startClock(now)
+}
+
+// startClock starts a fresh turn on the clock for the player who needs to act, without charging
+// anyone for the turn that came before it. It must be called with the lock held.
+func (r *gameRouter) startClock(now time.Time) {
+	r.stopClock()
+	r.clock.Player = ""
+	if r.game == nil || r.game.Finished {
+		return
+	}
+	player, err := bots.Actor(r.game)
+	if err != nil {
+		log.Printf("failed to start the clock in game %q: %v", r.id, err)
+		return
+	}
+
+	r.clock.Player = player
+	r.clock.Started = now
+	turn := r.clockTurn
+	r.clockTimer = time.AfterFunc(r.clock.deadline().Sub(now), func() { r.clockExpired(turn) })
+}
+
+// stopClock cancels the timer for the current turn. The turn counter is bumped as well, since a
+// timer that has already fired could still be waiting on the lock. It must be called with the lock
+// held.
+func (r *gameRouter) stopClock() {
+	r.clockTurn += 1
+	if r.clockTimer != nil {
+		r.clockTimer.Stop()
+		r.clockTimer = nil
+	}
+}
+
+// clockExpired takes a default turn for the player who ran out of time: they pass in the market,
+// leave the company's inventory alone, and let the game choose how to handle its earnings. If
+// that isn't allowed a random legal move is taken instead, and if that fails too the clock is
+// restarted so the game never sits waiting without a clock.
+func (r *gameRouter) clockExpired(turn int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if turn != r.clockTurn || r.game == nil || r.game.Finished {
+		return
+	}
+
+	player := r.clock.Player
+	if errs := bots.Play(bots.Pass{}, r.game); len(errs) > 0 {
+		log.Printf("failed to pass for %s after they ran out of time in game %q: %v", player,
+			r.id, errs)
+		fallback, err := bots.New("random", r.game.Seed+int64(len(r.game.Log)))
+		if err == nil {
+			errs = bots.Play(fallback, r.game)
+		} else {
+			errs = []error{err}
+		}
+		if len(errs) > 0 {
+			log.Printf("failed to take the turn for %s after they ran out of time in game %q: %v",
+				player, r.id, errs)
+			r.restartExpiredClock()
+			return
+		}
+	}
+	var resp jsonResponse
+	r.save(&resp)
+	r.publish(turnEvent)
+	r.playBots()
+}
+
+// restartExpiredClock starts the clock again after the default turn for a player who ran out of
+// time couldn't be taken. The player is left with only the time for a turn, but never less than
+// expiredRetryDelay so clocks without any time per turn don't keep failing in a loop. It must be
+// called with the lock held.
+func (r *gameRouter) restartExpiredClock() {
+	r.resetClock()
+	if r.clockTimer != nil && time.Until(r.clock.deadline()) < expiredRetryDelay {
+		r.clockTimer.Reset(expiredRetryDelay)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gameState"
+)
+
+// TestTurnClockState checks to make sure the clock settings are used when a game is created, and
+// that the time left is shown with the state of the game.
+func TestTurnClockState(t *testing.T) {
+	handler := newLobbyRouter()
+	defer removeTestGame("turn_clock")
+
+	body := map[string]interface{}{
+		"id":         "bad_clock",
+		"players":    []string{"1st", "2nd"},
+		"turn_clock": map[string]int{"turn_seconds": -1},
+	}
+	if status, _ := doRequest(t, handler, "POST", "/games", body); status != 400 {
+		t.Errorf("creating game with a negative clock returned status %d, expected 400", status)
+	}
+
+	body["id"] = "turn_clock"
+	body["turn_clock"] = map[string]interface{}{
+		"turn_seconds": 60,
+		"bank_seconds": 30,
+		"player_banks": map[string]int{"2nd": 90},
+	}
+	if status, resp := doRequest(t, handler, "POST", "/games", body); status != 201 {
+		t.Fatalf("creating game returned status %d: %+v", status, resp)
+	}
+	mapLock.RLock()
+	router := activeGames["turn_clock"]
+	mapLock.RUnlock()
+	defer func() {
+		router.lock.Lock()
+		router.stopClock()
+		router.lock.Unlock()
+	}()
+
+	status, resp := doRequest(t, handler, "GET", "/turn_clock/state", nil)
+	if status != 200 {
+		t.Fatalf("getting state returned status %d: %+v", status, resp)
+	}
+	buf, _ := json.Marshal(resp.Result)
+	var state struct {
+		Round int          `json:"round"`
+		Clock *clockStatus `json:"clock"`
+	}
+	if err := json.Unmarshal(buf, &state); err != nil {
+		t.Fatalf("failed to decode state: %v", err)
+	}
+
+	if state.Round != 1 {
+		t.Errorf("state has round %d, expected the rest of the state to be included", state.Round)
+	}
+	if state.Clock == nil {
+		t.Fatal("state has no clock")
+	} else if current := router.game.TurnManager.Current(); state.Clock.Player != current {
+		t.Errorf("clock is running for %q instead of %q", state.Clock.Player, current)
+	}
+	if left := state.Clock.TurnLeft; left <= 50 || left > 60 {
+		t.Errorf("clock shows %v seconds left in the turn, expected about 60", left)
+	}
+	if banks := state.Clock.Banks; banks["1st"] != 30 || banks["2nd"] != 90 {
+		t.Errorf("clock shows banks %v, expected 30 for 1st and 90 for 2nd", banks)
+	}
+}
+
+// TestTurnClockTimeout checks to make sure a player who runs out of time passes automatically,
+// and that the overtime they used comes out of their bank.
+func TestTurnClockTimeout(t *testing.T) {
+	handler, router := newTestRouter(t, "clock_timeout", []string{"1st", "2nd"})
+	defer removeTestGame("clock_timeout")
+
+	router.lock.Lock()
+	first := router.game.TurnManager.Current()
+	router.clock = &turnClock{
+		TurnTime:    10 * time.Millisecond,
+		DefaultBank: 20 * time.Millisecond,
+		Banks:       map[string]time.Duration{first: time.Second},
+	}
+	router.startClock(time.Now())
+	router.lock.Unlock()
+	defer func() {
+		router.lock.Lock()
+		router.stopClock()
+		router.lock.Unlock()
+	}()
+
+	// Taking a turn in time should leave the player's bank alone.
+	if player := passTurn(t, handler, router); player != first {
+		t.Fatalf("%s passed instead of %s", player, first)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		router.lock.RLock()
+		actions := len(router.game.Log)
+		router.lock.RUnlock()
+		if actions > 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	router.lock.RLock()
+	defer router.lock.RUnlock()
+	if len(router.game.Log) < 2 {
+		t.Fatal("no turn was taken after the clock ran out")
+	}
+	action := router.game.Log[1]
+	if action.Player == first || action.Market == nil || action.Market.Purchase != nil ||
+		len(action.Market.Sales) > 0 || action.Market.Buyback != nil {
+		t.Errorf("expected the second player to pass after running out of time, got %+v", action)
+	}
+	if bank := router.clock.Banks[action.Player]; bank != 0 {
+		t.Errorf("%s has %v left in their bank after running out of time", action.Player, bank)
+	}
+	if bank := router.clock.bank(first); bank < 900*time.Millisecond {
+		t.Errorf("%s has %v left in their bank after acting in time", first, bank)
+	}
+}
+
+// TestTurnClockFailedTimeout checks to make sure the clock keeps running when the default turn for
+// a player who ran out of time isn't allowed.
+func TestTurnClockFailedTimeout(t *testing.T) {
+	_, router := newTestRouter(t, "clock_failed", []string{"1st", "2nd"})
+	defer removeTestGame("clock_failed")
+
+	router.lock.Lock()
+	defer router.lock.Unlock()
+	// The company is stuck between stages, so no turn can be taken for its president.
+	const current = "1st"
+	router.game.Phase = 1
+	router.game.Stage = "stuck"
+	router.game.TurnManager = gameState.TurnManager{Order: []string{"Pennsylvania"}}
+	router.game.Companies["Pennsylvania"].President = current
+	router.clock = &turnClock{TurnTime: time.Hour}
+	router.startClock(time.Now())
+	defer router.stopClock()
+
+	turn := router.clockTurn
+	router.lock.Unlock()
+	router.clockExpired(turn)
+	router.lock.Lock()
+
+	if len(router.game.Log) != 0 {
+		t.Errorf("turn was taken for a company that can't act: %+v", router.game.Log)
+	}
+	if router.clockTimer == nil || router.clockTurn == turn || router.clock.Player != current {
+		t.Errorf("clock for %s stopped after the default turn failed: %+v", current,
+			*router.clock)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// The undoRequest struct keeps track of a request to undo the last action while the other players
+// decide whether to allow it. The request is tied to the length of the action log so it can never
+// apply to a different action than the one the players agreed to undo. Actions is how many actions
+// will be undone, which includes any bot turns taken after the player's action.
+type undoRequest struct {
+	Player    string          `json:"player"`
+	Action    int             `json:"-"`
+	Actions   int             `json:"actions"`
+	Approvals map[string]bool `json:"approvals"`
+}
+
+// approved checks if every player has agreed to the undo.
+func (u *undoRequest) approved(playerNames []string) bool {
+	for _, name := range playerNames {
+		if !u.Approvals[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// lastHumanAction finds the most recent action taken by a human, returning who took it and how
+// many actions from the end of the log it is. Bots take their turns as soon as the human's turn is
+// over, so their actions have to be undone along with it. It must be called with the lock held.
+func (r *gameRouter) lastHumanAction() (string, int) {
+	count := 1
+	for count < len(r.game.Log) && r.bots[r.game.Log[len(r.game.Log)-count].Player] != "" {
+		count += 1
+	}
+	return r.game.Log[len(r.game.Log)-count].Player, count
+}
+
+// performUndo rolls back the last actions and saves the game. The actions are undone on a copy so
+// the game is left alone if any of them can't be undone. It must be called with the lock held.
+func (r *gameRouter) performUndo(resp *jsonResponse, actions int) {
+	result, err := r.game.Copy()
+	for ind := 0; err == nil && ind < actions; ind += 1 {
+		err = result.Undo()
+	}
+	if err != nil {
+		resp.status = 409
+		resp.Errors = []string{err.Error()}
+	} else {
+		*r.game = *result
+		r.save(resp)
+		r.publish(undoEvent)
+	}
+}
+
+func (r *gameRouter) getUndo(writer http.ResponseWriter, request *http.Request) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	writeJson(&jsonResponse{Result: r.pendingUndo}, writer)
+}
+
+// requestUndo starts the process of undoing the last action. Only the player who took the action
+// can ask for it to be undone, and any bot turns taken after it are undone too. If the game
+// requires consent the other players must approve before anything happens, otherwise the action
+// is undone immediately.
+func (r *gameRouter) requestUndo(writer http.ResponseWriter, request *http.Request) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	player := r.seatPlayer(request)
+	if player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to undo an action"}
+		return
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if len(r.game.Log) == 0 {
+		resp.status = 409
+		resp.Errors = []string{"no actions to undo"}
+		return
+	}
+	last, actions := r.lastHumanAction()
+	if last != player {
+		resp.status = 403
+		resp.Errors = []string{fmt.Sprintf("only %s can undo their action", last)}
+		return
+	}
+
+	if !r.undoConsent {
+		r.performUndo(&resp, actions)
+		return
+	}
+	r.pendingUndo = &undoRequest{
+		Player:    player,
+		Action:    len(r.game.Log),
+		Actions:   actions,
+		Approvals: map[string]bool{player: true},
+	}
+	r.voteUndo(&resp, player, true)
+}
+
+func (r *gameRouter) approveUndo(writer http.ResponseWriter, request *http.Request) {
+	r.handleUndoVote(writer, request, true)
+}
+func (r *gameRouter) rejectUndo(writer http.ResponseWriter, request *http.Request) {
+	r.handleUndoVote(writer, request, false)
+}
+
+func (r *gameRouter) handleUndoVote(writer http.ResponseWriter, request *http.Request,
+	approve bool) {
+	resp := jsonResponse{}
+	defer writeJson(&resp, writer)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if player := r.seatPlayer(request); player == "" {
+		resp.status = 401
+		resp.Errors = []string{"a valid seat token is required to vote on an undo"}
+	} else if !r.checkVersion(writer, request, &resp) {
+		return
+	} else if r.pendingUndo == nil || r.pendingUndo.Action != len(r.game.Log) {
+		resp.status = 409
+		resp.Errors = []string{"no undo request is waiting for approval"}
+	} else {
+		r.voteUndo(&resp, player, approve)
+	}
+}
+
+// voteUndo records a player's vote on the pending undo. A single rejection cancels the request,
+// and once every human player approves the action is undone. Bots never vote, so they're left out.
+// It must be called with the lock held.
+func (r *gameRouter) voteUndo(resp *jsonResponse, player string, approve bool) {
+	if !approve {
+		r.pendingUndo = nil
+		return
+	}
+
+	r.pendingUndo.Approvals[player] = true
+	playerNames := make([]string, 0, len(r.game.Players))
+	for name := range r.game.Players {
+		if r.bots[name] == "" {
+			playerNames = append(playerNames, name)
+		}
+	}
+	sort.Strings(playerNames)
+
+	if r.pendingUndo.approved(playerNames) {
+		r.performUndo(resp, r.pendingUndo.Actions)
+	} else {
+		resp.status = 202
+		resp.Result = r.pendingUndo
+	}
+}
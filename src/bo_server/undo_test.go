@@ -0,0 +1,206 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"gameState"
+)
+
+// passTurn has the current player pass during the market phase, returning the player's name.
+func passTurn(t *testing.T, handler http.Handler, router *gameRouter) string {
+	current := router.game.TurnManager.Current()
+	status, resp := doSeatRequest(t, handler, seatToken(router, current), "POST",
+		"/"+router.id+"/market_turn", gameState.MarketTurn{})
+	if status != 200 {
+		t.Fatalf("%s's pass returned status %d: %+v", current, status, resp)
+	}
+	return current
+}
+
+// TestUndo checks to make sure only the player who took the last action can undo it, and that
+// undoing it puts the game back to the previous turn.
+func TestUndo(t *testing.T) {
+	const url = "/undo/undo"
+	handler, router := newTestRouter(t, "undo", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("undo")
+
+	current := router.game.TurnManager.Current()
+	token := seatToken(router, current)
+	if status, _ := doSeatRequest(t, handler, token, "POST", url, struct{}{}); status != 409 {
+		t.Errorf("undo before any actions returned status %d, expected 409", status)
+	}
+
+	passTurn(t, handler, router)
+	if status, _ := doRequest(t, handler, "POST", url, struct{}{}); status != 401 {
+		t.Errorf("undo without token returned status %d, expected 401", status)
+	}
+	other := seatToken(router, router.game.TurnManager.Current())
+	if status, _ := doSeatRequest(t, handler, other, "POST", url, struct{}{}); status != 403 {
+		t.Errorf("undo by another player returned status %d, expected 403", status)
+	}
+	if router.game.TurnManager.Number != 1 {
+		t.Fatalf("turn number is %d after rejected undos", router.game.TurnManager.Number)
+	}
+
+	if status, resp := doSeatRequest(t, handler, token, "POST", url, struct{}{}); status != 200 {
+		t.Errorf("%s's undo returned status %d: %+v", current, status, resp)
+	}
+	if router.game.TurnManager.Number != 0 || router.game.TurnManager.Current() != current {
+		t.Errorf("turn is %d for %s after undo, expected 0 for %s",
+			router.game.TurnManager.Number, router.game.TurnManager.Current(), current)
+	}
+}
+
+// TestUndoConsent checks to make sure games requiring consent only undo the action once every
+// player approves, and that a single rejection cancels the request.
+func TestUndoConsent(t *testing.T) {
+	const gameId = "undo_consent"
+	playerNames := []string{"1st", "2nd", "3rd"}
+	_, _, err := addNewGame(gameId, playerNames, 0, true, gameState.DefaultEndConditions,
+		gameState.DefaultGameOptions, clockSettings{})
+	if err != nil {
+		t.Fatalf("failed to create game %q: %v", gameId, err)
+	}
+	defer removeTestGame(gameId)
+	handler := mux.NewRouter()
+	initializeGameRoutes(handler)
+	mapLock.RLock()
+	router := activeGames[gameId]
+	mapLock.RUnlock()
+
+	// The first request is rejected, which should leave the game alone.
+	player := passTurn(t, handler, router)
+	token := seatToken(router, player)
+	status, _ := doSeatRequest(t, handler, token, "POST", "/undo_consent/undo", struct{}{})
+	if status != 202 {
+		t.Fatalf("undo request returned status %d, expected 202", status)
+	}
+	other := seatToken(router, router.game.TurnManager.Current())
+	status, _ = doSeatRequest(t, handler, other, "POST", "/undo_consent/undo/reject", struct{}{})
+	if status != 200 {
+		t.Errorf("undo rejection returned status %d", status)
+	} else if router.game.TurnManager.Number != 1 || router.pendingUndo != nil {
+		t.Errorf("rejected undo is still pending or changed the game")
+	}
+
+	// Taking another action should cancel the request, since the action is no longer the last.
+	doSeatRequest(t, handler, token, "POST", "/undo_consent/undo", struct{}{})
+	player = passTurn(t, handler, router)
+	token = seatToken(router, player)
+	status, _ = doSeatRequest(t, handler, other, "POST", "/undo_consent/undo/approve", struct{}{})
+	if status != 409 {
+		t.Errorf("approval after a new action returned status %d, expected 409", status)
+	}
+
+	doSeatRequest(t, handler, token, "POST", "/undo_consent/undo", struct{}{})
+	status, resp := doRequest(t, handler, "GET", "/undo_consent/undo", nil)
+	if status != 200 || resp == nil {
+		t.Errorf("getting the undo request returned status %d", status)
+	} else if pending, _ := resp.Result.(map[string]interface{}); pending["player"] != player {
+		t.Errorf("pending undo is %+v, expected one from %s", resp.Result, player)
+	}
+	voters := make([]string, 0, len(playerNames)-1)
+	for _, name := range playerNames {
+		if name != player {
+			voters = append(voters, name)
+		}
+	}
+	for ind, name := range voters {
+		expected := 202
+		if ind == len(voters)-1 {
+			expected = 200
+		}
+		status, resp := doSeatRequest(t, handler, seatToken(router, name), "POST",
+			"/undo_consent/undo/approve", struct{}{})
+		if status != expected {
+			t.Errorf("approval from %s returned status %d: %+v", name, status, resp)
+		}
+	}
+	if router.game.TurnManager.Number != 1 {
+		t.Errorf("turn number is %d after approved undo, expected 1",
+			router.game.TurnManager.Number)
+	}
+}
+
+// TestUndoConsentBots checks to make sure bots don't hold up undos in games requiring consent,
+// since they never vote.
+func TestUndoConsentBots(t *testing.T) {
+	const gameId = "undo_bots"
+	_, _, err := addNewGame(gameId, []string{"1st", "2nd", "3rd"}, 0, true,
+		gameState.DefaultEndConditions, gameState.DefaultGameOptions, clockSettings{})
+	if err != nil {
+		t.Fatalf("failed to create game %q: %v", gameId, err)
+	}
+	defer removeTestGame(gameId)
+	handler := mux.NewRouter()
+	initializeGameRoutes(handler)
+	mapLock.RLock()
+	router := activeGames[gameId]
+	mapLock.RUnlock()
+
+	// The last player in the order is taken over by a bot so the humans act first.
+	order := router.game.TurnManager.Order
+	bot := order[len(order)-1]
+	botToken := seatToken(router, bot)
+	router.lock.Lock()
+	delete(router.tokens, botToken)
+	router.bots = map[string]string{bot: "greedy"}
+	router.lock.Unlock()
+
+	player := passTurn(t, handler, router)
+	status, _ := doSeatRequest(t, handler, seatToken(router, player), "POST", "/undo_bots/undo",
+		struct{}{})
+	if status != 202 {
+		t.Fatalf("undo request returned status %d, expected 202", status)
+	}
+	other := seatToken(router, router.game.TurnManager.Current())
+	status, resp := doSeatRequest(t, handler, other, "POST", "/undo_bots/undo/approve", struct{}{})
+	if status != 200 {
+		t.Errorf("approval from the only other human returned status %d: %+v", status, resp)
+	} else if router.game.TurnManager.Number != 0 {
+		t.Errorf("turn number is %d after approved undo, expected 0",
+			router.game.TurnManager.Number)
+	}
+}
+
+// TestUndoBots checks to make sure a player can undo their action even though the bots after them
+// have already taken their turns, and that the bots' turns are undone along with it.
+func TestUndoBots(t *testing.T) {
+	handler, router := newTestRouter(t, "undo_after_bots", []string{"1st", "2nd", "3rd"})
+	defer removeTestGame("undo_after_bots")
+
+	// The second player in the order is taken over by a bot so it acts right after the first.
+	order := router.game.TurnManager.Order
+	human, bot, last := order[0], order[1], order[2]
+	botToken := seatToken(router, bot)
+	router.lock.Lock()
+	delete(router.tokens, botToken)
+	router.bots = map[string]string{bot: "pass"}
+	router.lock.Unlock()
+
+	passTurn(t, handler, router)
+	if number := router.game.TurnManager.Number; number != 2 || len(router.game.Log) != 2 {
+		t.Fatalf("game is on turn %d with %d actions after the bot's turn, expected 2 and 2",
+			number, len(router.game.Log))
+	}
+	status, _ := doSeatRequest(t, handler, seatToken(router, last), "POST",
+		"/undo_after_bots/undo", struct{}{})
+	if status != 403 {
+		t.Errorf("undo by a player who hasn't acted returned status %d, expected 403", status)
+	}
+
+	status, resp := doSeatRequest(t, handler, seatToken(router, human), "POST",
+		"/undo_after_bots/undo", struct{}{})
+	if status != 200 {
+		t.Fatalf("%s's undo returned status %d: %+v", human, status, resp)
+	}
+	if router.game.TurnManager.Number != 0 || len(router.game.Log) != 0 ||
+		router.game.TurnManager.Current() != human {
+		t.Errorf("turn is %d for %s with %d actions after undo, expected 0 for %s with none",
+			router.game.TurnManager.Number, router.game.TurnManager.Current(),
+			len(router.game.Log), human)
+	}
+}
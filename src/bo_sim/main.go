@@ -0,0 +1,72 @@
+// The bo_sim command plays games between bots without the server, reporting how each strategy did
+// and any states that broke the rules. Games are seeded from the seed flag so a run can always be
+// repeated exactly.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"bots"
+	"gameState"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "the number of games to play")
+	seed := flag.Int64("seed", 1, "the seed used to create every game's seed")
+	players := flag.String("players", "greedy,greedy,random",
+		fmt.Sprintf("comma separated strategies for each seat (options are %v)", bots.Names()))
+	maxRounds := flag.Int("max_rounds", 20, "rounds played before a game ends (0 for no limit)")
+	maxActions := flag.Int("max_actions", 5000, "actions allowed before a game is abandoned")
+	workers := flag.Int("workers", runtime.NumCPU(), "the number of games played at once")
+	flag.Parse()
+
+	strategies := strings.Split(*players, ",")
+	for _, strategy := range strategies {
+		if _, err := bots.New(strategy, 0); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	endConditions := gameState.DefaultEndConditions
+	endConditions.MaxRounds = *maxRounds
+
+	// Every game's seed is chosen up front and the results are added in order, so the report is
+	// the same no matter how many workers there are.
+	rng := rand.New(rand.NewSource(*seed))
+	queue := make(chan int, *games)
+	gameSeeds := make([]int64, *games)
+	results := make([]*gameResult, *games)
+	for ind := range gameSeeds {
+		gameSeeds[ind] = rng.Int63()
+		queue <- ind
+	}
+	close(queue)
+
+	var wait sync.WaitGroup
+	for worker := 0; worker < *workers; worker += 1 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for ind := range queue {
+				results[ind] = runGame(gameSeeds[ind], strategies, endConditions, *maxActions)
+			}
+		}()
+	}
+	wait.Wait()
+
+	result := newReport(strategies)
+	for _, game := range results {
+		result.add(game)
+	}
+
+	result.write(os.Stdout)
+	if len(result.Problems) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// The report struct collects the results of every simulated game. The averages only include the
+// games that finished, since the others were cut off before the end.
+type report struct {
+	Seats    []string
+	Games    int
+	Finished int
+	Rounds   int
+	Actions  int
+	Wins     map[string]int
+
+	TechGames     map[int]int
+	TechRounds    map[int]int
+	Started       map[string]int
+	Receiverships map[string]int
+
+	Problems []string
+}
+
+func newReport(strategies []string) *report {
+	result := &report{
+		Wins:          make(map[string]int),
+		TechGames:     make(map[int]int),
+		TechRounds:    make(map[int]int),
+		Started:       make(map[string]int),
+		Receiverships: make(map[string]int),
+	}
+	for ind, strategy := range strategies {
+		result.Seats = append(result.Seats, seatName(ind, strategy))
+	}
+	return result
+}
+
+// add includes a game's result in the report. Any violations or errors are kept along with the
+// game's seed so the game can be played again to find the problem.
+func (r *report) add(result *gameResult) {
+	r.Games += 1
+	for _, violation := range result.Violations {
+		r.Problems = append(r.Problems, fmt.Sprintf("seed %d: %s", result.Seed, violation))
+	}
+	if result.Err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("seed %d: %v", result.Seed, result.Err))
+	}
+
+	for level, round := range result.TechLevels {
+		r.TechGames[level] += 1
+		r.TechRounds[level] += round
+	}
+	for company := range result.Started {
+		r.Started[company] += 1
+	}
+	for company, count := range result.Receiverships {
+		r.Receiverships[company] += count
+	}
+
+	if result.Finished {
+		r.Finished += 1
+		r.Rounds += result.Rounds
+		r.Actions += result.Actions
+		for _, winner := range result.Winners {
+			r.Wins[winner] += 1
+		}
+	}
+}
+
+// percent formats a count as a percentage of the total, avoiding dividing by zero.
+func percent(count, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(count)/float64(total))
+}
+
+// average formats the sum divided by the count, avoiding dividing by zero.
+func average(sum, count int) string {
+	if count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", float64(sum)/float64(count))
+}
+
+func (r *report) write(writer io.Writer) {
+	fmt.Fprintf(writer, "games: %d played, %d finished\n", r.Games, r.Finished)
+	fmt.Fprintf(writer, "average length: %s rounds, %s actions\n",
+		average(r.Rounds, r.Finished), average(r.Actions, r.Finished))
+
+	fmt.Fprintln(writer, "\nwin rates (ties count for every winner):")
+	for _, seat := range r.Seats {
+		fmt.Fprintf(writer, "  %-12s %s\n", seat, percent(r.Wins[seat], r.Finished))
+	}
+
+	fmt.Fprintln(writer, "\ntech levels (games reaching it, average round first reached):")
+	for level := 1; level <= 6; level += 1 {
+		fmt.Fprintf(writer, "  %d  %-7s %s\n", level, percent(r.TechGames[level], r.Games),
+			average(r.TechRounds[level], r.TechGames[level]))
+	}
+
+	companies := make([]string, 0, len(r.Started))
+	for company := range r.Started {
+		companies = append(companies, company)
+	}
+	sort.Strings(companies)
+	fmt.Fprintln(writer, "\ncompanies (games started in, receiverships per game):")
+	for _, company := range companies {
+		fmt.Fprintf(writer, "  %-32s %-7s %s\n", company, percent(r.Started[company], r.Games),
+			average(r.Receiverships[company], r.Games))
+	}
+
+	fmt.Fprintf(writer, "\nproblems: %d\n", len(r.Problems))
+	for _, problem := range r.Problems {
+		fmt.Fprintf(writer, "  %s\n", problem)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"bots"
+	"gameState"
+)
+
+// The gameResult struct holds everything the report needs from a single simulated game.
+//
+// The tech levels map each level the game reached to the round it was first reached in. Started
+// holds the companies that were started at some point, and receiverships counts how many times
+// each company ended up with no players holding stock after being nonprofitable.
+type gameResult struct {
+	Seed     int64
+	Finished bool
+	Rounds   int
+	Actions  int
+	Winners  []string
+
+	TechLevels    map[int]int
+	Started       map[string]bool
+	Receiverships map[string]int
+
+	Violations []string
+	Err        error
+}
+
+// seatName is the name of the player in a seat, which includes the strategy so the standings can
+// be traced back to the strategy that earned them.
+func seatName(seat int, strategy string) string {
+	return fmt.Sprintf("%d-%s", seat+1, strategy)
+}
+
+// runGame plays a complete game between bots using the listed strategies, one for each seat. The
+// game stops early if it takes more than maxActions actions, since that means the bots have stopped
+// making progress. Every action is followed by a check of the game's invariants.
+func runGame(seed int64, strategies []string, endConditions gameState.EndConditions,
+	maxActions int) *gameResult {
+	result := &gameResult{
+		Seed:          seed,
+		TechLevels:    map[int]int{1: 0},
+		Started:       make(map[string]bool),
+		Receiverships: make(map[string]int),
+	}
+
+	names := make([]string, len(strategies))
+	seats := make(map[string]bots.Strategy, len(strategies))
+	for ind, strategy := range strategies {
+		names[ind] = seatName(ind, strategy)
+		bot, err := bots.New(strategy, seed+int64(ind))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		seats[names[ind]] = bot
+	}
+	game := gameState.NewSeededGame(names, seed)
+	game.EndConditions = endConditions
+
+	for len(game.Log) < maxActions && !game.Finished {
+		actor, err := bots.Actor(game)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		presidents := make(map[string]string, len(game.Companies))
+		for name, company := range game.Companies {
+			presidents[name] = company.President
+		}
+
+		if errs := bots.Play(seats[actor], game); len(errs) > 0 {
+			result.Err = fmt.Errorf("%s's turn failed after %d actions: %v", actor,
+				len(game.Log), errs)
+			break
+		}
+
+		if _, reached := result.TechLevels[game.TechLevel]; !reached {
+			result.TechLevels[game.TechLevel] = game.Round
+		}
+		for name, company := range game.Companies {
+			if company.StockPrice > 0 {
+				result.Started[name] = true
+			}
+			if presidents[name] != "" && company.President == "" {
+				result.Receiverships[name] += 1
+			}
+		}
+		for _, err := range game.CheckInvariants() {
+			result.Violations = append(result.Violations,
+				fmt.Sprintf("after action %d: %v", len(game.Log), err))
+		}
+	}
+
+	result.Finished = game.Finished
+	result.Rounds = game.Round
+	result.Actions = len(game.Log)
+	for _, standing := range game.Standings {
+		if standing.Rank == 1 {
+			result.Winners = append(result.Winners, standing.Player)
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gameState"
+)
+
+// TestRunGame plays a few short games to make sure they finish without breaking any invariants,
+// and that playing a game again with the same seed gives the same result.
+func TestRunGame(t *testing.T) {
+	strategies := []string{"greedy", "random", "greedy"}
+	endConditions := gameState.EndConditions{MaxRounds: 4, LastTrain: true}
+
+	result := newReport(strategies)
+	for seed := int64(0); seed < 5; seed += 1 {
+		game := runGame(seed, strategies, endConditions, 5000)
+		if game.Err != nil || len(game.Violations) > 0 {
+			t.Errorf("game with seed %d failed: %v %v", seed, game.Err, game.Violations)
+		} else if !game.Finished || game.Rounds != 4 || len(game.Winners) == 0 {
+			t.Errorf("game with seed %d didn't finish properly: %+v", seed, game)
+		}
+		again := runGame(seed, strategies, endConditions, 5000)
+		if !reflect.DeepEqual(game, again) {
+			t.Errorf("game with seed %d played differently the second time", seed)
+		}
+		result.add(game)
+	}
+
+	var buf bytes.Buffer
+	result.write(&buf)
+	if !strings.Contains(buf.String(), "games: 5 played, 5 finished") {
+		t.Errorf("report doesn't include the games:\n%s", buf.String())
+	}
+
+	if game := runGame(0, strategies, endConditions, 10); game.Finished || game.Actions != 10 {
+		t.Errorf("game limited to 10 actions has result %+v", game)
+	}
+	if game := runGame(0, []string{"not a strategy"}, endConditions, 10); game.Err == nil {
+		t.Error("game with an unknown strategy didn't fail")
+	}
+}
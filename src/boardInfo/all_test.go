@@ -27,12 +27,30 @@ func TestBuildCost(t *testing.T) {
 	}
 
 	for _, pair := range testVals {
-		if cost := BuildCost(pair.hexcoord); cost != pair.cost {
+		if cost := DefaultBoard.BuildCost(pair.hexcoord); cost != pair.cost {
 			t.Errorf("expected hex %q to cost $%d, but got $%d", pair.hexcoord, pair.cost, cost)
 		}
 	}
 }
 
+func TestAllHexes(t *testing.T) {
+	hexes := DefaultBoard.AllHexes()
+	if !sort.StringsAreSorted(hexes) {
+		t.Errorf("hexes %q are not sorted", hexes)
+	}
+	for _, coord := range hexes {
+		if DefaultBoard.BuildCost(coord) == 0 {
+			t.Errorf("hex %q has no build cost", coord)
+		}
+	}
+	for _, city := range DefaultBoard.AllCities() {
+		ind := sort.SearchStrings(hexes, city.Location)
+		if ind == len(hexes) || hexes[ind] != city.Location {
+			t.Errorf("%s (%s) is missing from the hexes", city.Name, city.Location)
+		}
+	}
+}
+
 func TestTrainCost(t *testing.T) {
 	type testPair struct{ trainNum, cost int }
 	testVals := []testPair{
@@ -105,7 +123,8 @@ func TestTileAdjacency(t *testing.T) {
 	}
 
 	for _, set := range testVals {
-		if adjacent := TilesAdjacent(set.coordA, set.coordB); adjacent != set.adjacent {
+		adjacent := DefaultBoard.TilesAdjacent(set.coordA, set.coordB)
+		if adjacent != set.adjacent {
 			if set.adjacent {
 				t.Errorf("expected %q and %q to be adjacent", set.coordA, set.coordB)
 			} else {
@@ -139,7 +158,7 @@ func TestTileContinuity(t *testing.T) {
 	}
 
 	for _, set := range testVals {
-		if set.connected != TilesContiguous(set.existent, set.updates) {
+		if set.connected != DefaultBoard.TilesContiguous(set.existent, set.updates) {
 			if set.connected {
 				t.Errorf("expected %q and %q to be contiguous", set.existent, set.updates)
 			} else {
@@ -165,12 +184,40 @@ func TestStartingCity(t *testing.T) {
 	}
 
 	for _, pair := range testVals {
-		if value := StartingLocation(pair.company); value != pair.hexcoord {
+		if value := DefaultBoard.StartingLocation(pair.company); value != pair.hexcoord {
 			t.Errorf("expected %s to start on %q, not %q", pair.company, pair.hexcoord, value)
 		}
 	}
 }
 
+func TestHomeCities(t *testing.T) {
+	testVals := map[string][]string{
+		"Pennsylvania":     {"G16", "G20", "G24"},
+		"New York Central": {"D19", "D21", "D23", "D25", "F25"},
+		"Erie":             nil,
+	}
+
+	for company, expected := range testVals {
+		if value := DefaultBoard.HomeCities(company); !reflect.DeepEqual(value, expected) {
+			t.Errorf("expected %s to have home cities %q, not %q", company, expected, value)
+		}
+	}
+}
+
+func TestSortCities(t *testing.T) {
+	for techLvl := 1; techLvl <= 6; techLvl += 1 {
+		cityList := DefaultBoard.AllCities()
+		SortCities(cityList, techLvl)
+		for ind := 1; ind < len(cityList); ind += 1 {
+			prev, cur := cityList[ind-1], cityList[ind]
+			if prev.Revenue[techLvl-1] < cur.Revenue[techLvl-1] {
+				t.Errorf("tech level %d: %s ($%d) sorted before %s ($%d)", techLvl,
+					prev.Name, prev.Revenue[techLvl-1], cur.Name, cur.Revenue[techLvl-1])
+			}
+		}
+	}
+}
+
 func TestCityList(t *testing.T) {
 	type testPair struct{ hexes, cities []string }
 	testVals := []testPair{
@@ -185,7 +232,7 @@ func TestCityList(t *testing.T) {
 	}
 
 	for _, pair := range testVals {
-		cityList := Cities(pair.hexes...)
+		cityList := DefaultBoard.Cities(pair.hexes...)
 		cityNames := make([]string, 0, len(cityList))
 		for _, city := range cityList {
 			cityNames = append(cityNames, city.Name)
@@ -233,3 +280,135 @@ func TestStockDecreate(t *testing.T) {
 			badPrice, prices[0], value)
 	}
 }
+
+func TestIsStockPrice(t *testing.T) {
+	for _, price := range StartingStockPrices(rand.Intn(5) + 1) {
+		if !IsStockPrice(price) {
+			t.Errorf("starting price %d is not a stock price", price)
+		}
+	}
+	for _, price := range []int{0, 35, 376} {
+		if IsStockPrice(price) {
+			t.Errorf("bad price %d is a stock price", price)
+		}
+	}
+}
+
+// smallBoard is a board with a single row of four hexes, where the border between the last two
+// can't be crossed.
+const smallBoard = `{
+	"hexes": {
+		"A1": {"build_cost": 10, "city": {"name": "West", "revenue": [10, 20, 30, 40, 50, 60],
+			"starting": "Erie"}},
+		"A3": {"build_cost": 20, "coal": true},
+		"A5": {"build_cost": 30, "city": {"name": "East", "revenue": [60, 50, 40, 30, 20, 10],
+			"exception": "Erie"}},
+		"A7": {"build_cost": 40}
+	},
+	"blocked_edges": [["A7", "A5"]]
+}`
+
+func TestLoadBoard(t *testing.T) {
+	board, err := LoadBoard([]byte(smallBoard))
+	if err != nil {
+		t.Fatalf("failed to load board: %v", err)
+	}
+
+	if hexes := board.AllHexes(); !reflect.DeepEqual(hexes, []string{"A1", "A3", "A5", "A7"}) {
+		t.Errorf("board has hexes %q", hexes)
+	}
+	if cost := board.BuildCost("A5"); cost != 30 {
+		t.Errorf("expected A5 to cost $30, got $%d", cost)
+	}
+	if location := board.StartingLocation("Erie"); location != "A1" {
+		t.Errorf("expected Erie to start in A1, got %q", location)
+	}
+	if home := board.HomeCities("Erie"); !reflect.DeepEqual(home, []string{"A5"}) {
+		t.Errorf("expected Erie's home cities to be A5, got %q", home)
+	}
+	if cities := board.Cities("A3", "A5"); len(cities) != 1 || cities[0].Name != "East" ||
+		cities[0].Location != "A5" {
+		t.Errorf("expected only East in A5, got %+v", cities)
+	}
+
+	if !board.TilesContiguous([]string{"A1"}, []string{"A3", "A5"}) {
+		t.Error("first three hexes aren't contiguous")
+	}
+	if board.TilesAdjacent("A5", "A7") || board.TilesContiguous([]string{"A1"}, []string{"A7"}) {
+		t.Error("track crossed the blocked edge")
+	}
+
+	// Nothing returned from the board can be used to change it.
+	board.StartingCoal()[0] = "A1"
+	board.AllCities()[0].Revenue[0] = 100
+	if coal := board.StartingCoal(); !reflect.DeepEqual(coal, []string{"A3"}) {
+		t.Errorf("board's coal changed to %q", coal)
+	}
+	if revenue := board.Cities("A1")[0].Revenue[0]; revenue != 10 {
+		t.Errorf("West's revenue changed to %d", revenue)
+	}
+}
+
+func TestLoadBoardInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no hexes":       `{"hexes": {}}`,
+		"unknown field":  `{"hexes": {"A1": {"build_cost": 10, "cost": 10}}}`,
+		"bad coordinate": `{"hexes": {"1A": {"build_cost": 10}}}`,
+		"leading zero":   `{"hexes": {"A01": {"build_cost": 10}}}`,
+		"between hexes":  `{"hexes": {"A1": {"build_cost": 10}, "A2": {"build_cost": 10}}}`,
+		"free hex":       `{"hexes": {"A1": {"build_cost": 0}}}`,
+		"unnamed city":   `{"hexes": {"A1": {"build_cost": 10, "city": {}}}}`,
+		"negative revenue": `{"hexes": {"A1": {"build_cost": 10,
+			"city": {"name": "A", "revenue": [-10, 0, 0, 0, 0, 0]}}}}`,
+		"two starts": `{"hexes": {
+			"A1": {"build_cost": 10, "city": {"name": "A", "starting": "Erie"}},
+			"A3": {"build_cost": 10, "city": {"name": "B", "starting": "Erie"}}}}`,
+		"blocked off the board": `{"hexes": {"A1": {"build_cost": 10}},
+			"blocked_edges": [["A1", "A3"]]}`,
+		"blocked between strangers": `{"hexes": {"A1": {"build_cost": 10},
+			"A5": {"build_cost": 10}}, "blocked_edges": [["A1", "A5"]]}`,
+	}
+	for name, definition := range tests {
+		if _, err := LoadBoard([]byte(definition)); err == nil {
+			t.Errorf("board with %s was loaded", name)
+		}
+	}
+}
+
+func TestRegisterBoard(t *testing.T) {
+	board, err := LoadBoard([]byte(smallBoard))
+	if err != nil {
+		t.Fatalf("failed to load board: %v", err)
+	}
+	if err := RegisterBoard("register_test", board); err != nil {
+		t.Fatalf("failed to register board: %v", err)
+	}
+	defer UnregisterBoard("register_test")
+
+	if found := FindBoard("register_test"); found != board {
+		t.Error("registered board wasn't found")
+	}
+	if FindBoard("") != DefaultBoard || FindBoard(StandardBoard) != DefaultBoard {
+		t.Error("standard board isn't the default board")
+	}
+	if FindBoard("not a board") != nil {
+		t.Error("found a board that was never registered")
+	}
+	names := BoardNames()
+	if ind := sort.SearchStrings(names, "register_test"); !sort.StringsAreSorted(names) ||
+		ind == len(names) || names[ind] != "register_test" {
+		t.Errorf("board names %q are missing the registered board or aren't sorted", names)
+	}
+
+	if err := RegisterBoard("register_test", DefaultBoard); err == nil {
+		t.Error("registered two boards with the same name")
+	} else if FindBoard("register_test") != board {
+		t.Error("failed registration replaced the board")
+	}
+	if err := RegisterBoard("", board); err == nil {
+		t.Error("registered a board without a name")
+	}
+	if err := RegisterBoard("empty", nil); err == nil {
+		t.Error("registered an empty board")
+	}
+}
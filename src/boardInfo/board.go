@@ -0,0 +1,173 @@
+package boardInfo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The Board struct holds everything about a single map: the cost to build track on each hex, the
+// cities and the companies they belong to, where the coal starts, and the borders between hexes
+// that can't be crossed. Boards are only created by LoadBoard and never change afterwards, so
+// everything the methods return is a copy the caller is free to modify.
+type Board struct {
+	hexes   map[string]hex
+	coal    []string
+	blocked map[[2]string]bool
+
+	startingLocations map[string]string
+	homeCities        map[string][]string
+}
+
+// The boardDefinition struct is the format boards are described in. Each hex is keyed by its
+// coordinate, which is a row letter followed by a column number. The columns only use every other
+// number within a row, so two hexes are next to each other if they are in the same row with
+// columns 2 apart, or in adjacent rows with columns 1 apart. Blocked edges list pairs of hexes
+// that would otherwise be next to each other but have an impassable border between them.
+type boardDefinition struct {
+	Hexes        map[string]hexDefinition `json:"hexes"`
+	BlockedEdges [][2]string              `json:"blocked_edges"`
+}
+
+type hexDefinition struct {
+	BuildCost int   `json:"build_cost"`
+	City      *City `json:"city,omitempty"`
+	Coal      bool  `json:"coal,omitempty"`
+}
+
+//go:embed default_board.json
+var defaultBoardJson []byte
+
+// DefaultBoard is the standard map, which games are played on unless they pick another board.
+var DefaultBoard = mustLoadBoard(defaultBoardJson)
+
+func mustLoadBoard(data []byte) *Board {
+	board, err := LoadBoard(data)
+	if err != nil {
+		panic(err)
+	}
+	return board
+}
+
+// LoadBoard creates a board from its JSON definition, making sure it describes a map the game can
+// be played on. Every problem found is included in the error.
+func LoadBoard(data []byte) (*Board, error) {
+	var definition boardDefinition
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("failed to read board: %v", err)
+	}
+
+	var problems []string
+	if len(definition.Hexes) == 0 {
+		problems = append(problems, "board has no hexes")
+	}
+	result := &Board{
+		hexes:             make(map[string]hex, len(definition.Hexes)),
+		blocked:           make(map[[2]string]bool, len(definition.BlockedEdges)),
+		startingLocations: make(map[string]string),
+		homeCities:        make(map[string][]string),
+	}
+
+	parity := -1
+	for _, coord := range sortedKeys(definition.Hexes) {
+		info := definition.Hexes[coord]
+		row, col, ok := parseCoord(coord)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q is not a hex coordinate", coord))
+			continue
+		} else if parity == -1 {
+			parity = (row + col) % 2
+		} else if (row+col)%2 != parity {
+			problems = append(problems, fmt.Sprintf("%s is between the other hexes", coord))
+		}
+		if info.BuildCost <= 0 {
+			problems = append(problems, fmt.Sprintf("%s has a build cost of $%d", coord,
+				info.BuildCost))
+		}
+
+		if info.Coal {
+			result.coal = append(result.coal, coord)
+		}
+		if info.City != nil {
+			city := *info.City
+			city.Location = coord
+			problems = append(problems, result.addCity(city)...)
+			info.City = &city
+		}
+		result.hexes[coord] = hex{BuildCost: info.BuildCost, City: info.City, Coal: info.Coal}
+	}
+
+	for _, edge := range definition.BlockedEdges {
+		if _, exists := result.hexes[edge[0]]; !exists {
+			problems = append(problems, fmt.Sprintf("blocked edge %v isn't on the board", edge))
+		} else if _, exists := result.hexes[edge[1]]; !exists {
+			problems = append(problems, fmt.Sprintf("blocked edge %v isn't on the board", edge))
+		} else if !neighbors(edge[0], edge[1]) {
+			problems = append(problems, fmt.Sprintf("blocked edge %v isn't between neighbors",
+				edge))
+		}
+		result.blocked[edge] = true
+		result.blocked[[2]string{edge[1], edge[0]}] = true
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid board: %s", strings.Join(problems, "; "))
+	}
+	return result, nil
+}
+
+// addCity adds the city to the look-ups for the starting locations and home cities, returning a
+// description of each problem with it.
+func (b *Board) addCity(city City) []string {
+	var problems []string
+	if city.Name == "" {
+		problems = append(problems, fmt.Sprintf("city in %s has no name", city.Location))
+	}
+	for level, revenue := range city.Revenue {
+		if revenue < 0 {
+			problems = append(problems, fmt.Sprintf("%s has $%d revenue at tech level %d",
+				city.Name, revenue, level+1))
+		}
+	}
+
+	if city.Starting != "" {
+		if other, exists := b.startingLocations[city.Starting]; exists {
+			problems = append(problems, fmt.Sprintf("%s starts in both %s and %s",
+				city.Starting, other, city.Location))
+		}
+		b.startingLocations[city.Starting] = city.Location
+	}
+	if city.Exception != "" {
+		b.homeCities[city.Exception] = append(b.homeCities[city.Exception], city.Location)
+	}
+	return problems
+}
+
+// parseCoord splits a hex coordinate into its row, starting at 0 for A, and its column. The
+// coordinate must be written exactly the way the board writes it, so there is only ever one way
+// to refer to each hex.
+func parseCoord(coord string) (row, col int, ok bool) {
+	if len(coord) < 2 || coord[0] < 'A' || coord[0] > 'Z' {
+		return 0, 0, false
+	}
+	col, err := strconv.Atoi(coord[1:])
+	if err != nil || col < 0 || strconv.Itoa(col) != coord[1:] {
+		return 0, 0, false
+	}
+	return int(coord[0] - 'A'), col, true
+}
+
+func sortedKeys(hexes map[string]hexDefinition) []string {
+	result := make([]string, 0, len(hexes))
+	for coord := range hexes {
+		result = append(result, coord)
+	}
+	sort.Strings(result)
+	return result
+}
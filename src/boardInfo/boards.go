@@ -0,0 +1,63 @@
+package boardInfo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// StandardBoard is the name the default board is registered under. Games that don't pick a board
+// are played on it.
+const StandardBoard = "standard"
+
+var (
+	boardLock sync.RWMutex
+	boards    = map[string]*Board{StandardBoard: DefaultBoard}
+)
+
+// RegisterBoard makes the board available to games under the name. Names can't be reused, since
+// games that were already created on a board have to keep being played on it.
+func RegisterBoard(name string, board *Board) error {
+	boardLock.Lock()
+	defer boardLock.Unlock()
+	if name == "" {
+		return fmt.Errorf("board must have a name")
+	} else if board == nil {
+		return fmt.Errorf("board %q is empty", name)
+	} else if _, exists := boards[name]; exists {
+		return fmt.Errorf("board %q already exists", name)
+	}
+	boards[name] = board
+	return nil
+}
+
+// FindBoard returns the board registered under the name, or nil if there isn't one. An empty name
+// refers to the standard board.
+func FindBoard(name string) *Board {
+	if name == "" {
+		name = StandardBoard
+	}
+	boardLock.RLock()
+	defer boardLock.RUnlock()
+	return boards[name]
+}
+
+// BoardNames returns the names of all the registered boards in sorted order.
+func BoardNames() []string {
+	boardLock.RLock()
+	defer boardLock.RUnlock()
+	result := make([]string, 0, len(boards))
+	for name := range boards {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// unregisterBoard removes the board registered under the name, so tests can clean up after
+// themselves.
+func unregisterBoard(name string) {
+	boardLock.Lock()
+	defer boardLock.Unlock()
+	delete(boards, name)
+}
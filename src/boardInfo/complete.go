@@ -2,6 +2,9 @@
 // between games or at any point during a game. Because go does not allow anything but primitives
 // to be constants this is a separate package largely to make sure this information is never
 // accidentally modified.
+//
+// The board itself is loaded from a definition, so other maps can be described the same way as
+// the standard one in default_board.json.
 package boardInfo
 
 import (
@@ -16,35 +19,14 @@ type hex struct {
 	Coal      bool  `json:"coal"`
 }
 
-var completeMap map[string]hex
-
-func init() {
-	completeMap = make(map[string]hex, len(buildCosts))
-
-	for coord, cost := range buildCosts {
-		var curCity *City
-		if val, ok := cities[coord]; ok {
-			curCity = &val
-		}
-		completeMap[coord] = hex{
-			BuildCost: cost,
-			City:      curCity,
-		}
-	}
-
-	for _, coord := range StartingCoal() {
-		curHex := completeMap[coord]
-		curHex.Coal = true
-		completeMap[coord] = curHex
-	}
-}
-
-func JsonMap() ([]byte, error) {
-	return json.Marshal(completeMap)
+// JsonMap describes every hex on the board, keyed by its coordinate.
+func (b *Board) JsonMap() ([]byte, error) {
+	return json.Marshal(b.hexes)
 }
 
-func StartingCoal() []string {
-	return []string{"G18", "H17", "I16", "J15", "K14"}
+// StartingCoal returns the hexes that start with coal that can be mined, in sorted order.
+func (b *Board) StartingCoal() []string {
+	return append([]string(nil), b.coal...)
 }
 
 func absInt(num int) int {
@@ -54,16 +36,17 @@ func absInt(num int) int {
 	return num
 }
 
-// TilesAdjacent checks if two map coordinates are next to each other.
-func TilesAdjacent(coordA, coordB string) bool {
-	// Handle the exceptions created by the impassable borders.
-	if (coordA == "I22" && coordB == "I24") || (coordB == "I22" && coordA == "I24") {
-		return false
-	}
-	if (coordA == "E12" && coordB == "F13") || (coordB == "E12" && coordA == "F13") {
+// TilesAdjacent checks if two map coordinates are next to each other without an impassable border
+// between them.
+func (b *Board) TilesAdjacent(coordA, coordB string) bool {
+	if b.blocked[[2]string{coordA, coordB}] {
 		return false
 	}
+	return neighbors(coordA, coordB)
+}
 
+// neighbors checks if two map coordinates are next to each other, ignoring any borders.
+func neighbors(coordA, coordB string) bool {
 	// If the hexes aren't in the same or adjacent row then they cannot be adjacent. We have to
 	// cast to int before the subtraction since the byte is an unsigned value.
 	rowDiff := absInt(int(coordA[0]) - int(coordB[0]))
@@ -89,9 +72,9 @@ func TilesAdjacent(coordA, coordB string) bool {
 }
 
 // tileConnected checks if a map coordinate has any adjacent tiles within a list of coordinates.
-func tileConnected(coord string, blob []string) bool {
+func (b *Board) tileConnected(coord string, blob []string) bool {
 	for _, value := range blob {
-		if TilesAdjacent(coord, value) {
+		if b.TilesAdjacent(coord, value) {
 			return true
 		}
 	}
@@ -106,11 +89,11 @@ func tileConnected(coord string, blob []string) bool {
 // to any in the existent list there is no point in checking those relations again, so we create
 // a new existent list with the updates that were established as contiguous and check the ones
 // that have not yet been confirm against them.
-func TilesContiguous(existent, update []string) bool {
+func (b *Board) TilesContiguous(existent, update []string) bool {
 	connected := make([]string, 0, len(update))
 	isolated := make([]string, 0, len(update))
 	for _, coord := range update {
-		if tileConnected(coord, existent) {
+		if b.tileConnected(coord, existent) {
 			connected = append(connected, coord)
 		} else {
 			isolated = append(isolated, coord)
@@ -124,7 +107,7 @@ func TilesContiguous(existent, update []string) bool {
 		// No addition tiles were establish as connected so there's nothing left to check
 		return false
 	}
-	return TilesContiguous(connected, isolated)
+	return b.TilesContiguous(connected, isolated)
 }
 
 // TechLevel converts the number of trains that have been bought during the game into the
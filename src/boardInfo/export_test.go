@@ -0,0 +1,4 @@
+package boardInfo
+
+// UnregisterBoard lets the tests remove the boards they register.
+var UnregisterBoard = unregisterBoard
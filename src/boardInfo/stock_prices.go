@@ -60,3 +60,14 @@ func StartingStockPrices(techLevel int) [3]int {
 		stockPrices[6+techLevel],
 	}
 }
+
+// MaxStockPrice returns the highest price a company's stock can reach.
+func MaxStockPrice() int {
+	return stockPrices[len(stockPrices)-1]
+}
+
+// IsStockPrice checks if the price is one of the spaces on the stock price track.
+func IsStockPrice(price int) bool {
+	ind := sort.SearchInts(stockPrices, price)
+	return ind < len(stockPrices) && stockPrices[ind] == price
+}
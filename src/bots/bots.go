@@ -0,0 +1,84 @@
+// Package bots contains the computer-controlled players. Each bot is a Strategy that decides what
+// to do on its turns, and the server takes the turns for any seat assigned to a bot.
+package bots
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"gameState"
+)
+
+// The Strategy interface is what a bot uses to decide its turns. Each method is only called when
+// it is the player's turn to take that kind of action, and the game it is given is a separate
+// copy, so any changes the strategy makes to it have no effect on the real game.
+//
+// The turns returned should always be legal. If one isn't the bot's turn fails and the game waits
+// for it to be taken some other way.
+type Strategy interface {
+	MarketTurn(game *gameState.Game, player string) gameState.MarketTurn
+	CompanyInventory(game *gameState.Game, player string) gameState.CompanyInventory
+	CompanyEarnings(game *gameState.Game, player string) gameState.CompanyEarnings
+}
+
+// strategies holds the constructors for every strategy, keyed by the names used to pick them.
+var strategies = map[string]func(rng *rand.Rand) Strategy{
+	"random": func(rng *rand.Rand) Strategy { return &Random{rng: rng} },
+	"greedy": func(rng *rand.Rand) Strategy { return Greedy{} },
+	"pass":   func(rng *rand.Rand) Strategy { return Pass{} },
+}
+
+// Names returns the names of all the available strategies in sorted order.
+func Names() []string {
+	result := make([]string, 0, len(strategies))
+	for name := range strategies {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// New creates the strategy with the provided name. Strategies that make random decisions use the
+// seed so the same bot will make the same decisions in the same game.
+func New(name string, seed int64) (Strategy, error) {
+	constructor := strategies[name]
+	if constructor == nil {
+		return nil, fmt.Errorf("no bot strategy named %q (options are %v)", name, Names())
+	}
+	return constructor(rand.New(rand.NewSource(seed))), nil
+}
+
+// Actor returns the name of the player who needs to act next, which during the business phases
+// is the president of the company whose turn it is.
+func Actor(game *gameState.Game) (string, error) {
+	if game.Finished {
+		return "", fmt.Errorf("the game is over, nobody needs to act")
+	} else if game.Phase.Market() {
+		return game.TurnManager.Current(), nil
+	}
+	return game.Companies[game.TurnManager.Current()].President, nil
+}
+
+// Play asks the strategy for the current turn and takes it for the player who needs to act.
+func Play(strategy Strategy, game *gameState.Game) []error {
+	player, err := Actor(game)
+	if err != nil {
+		return []error{err}
+	}
+	view, err := game.Copy()
+	if err != nil {
+		return []error{err}
+	}
+
+	switch {
+	case game.Phase.Market():
+		return game.PerformMarketTurn(player, strategy.MarketTurn(view, player))
+	case game.Stage == "inventory":
+		update := strategy.CompanyInventory(view, player)
+		return game.UpdateCompanyInventory(player, update)
+	default:
+		earnings := strategy.CompanyEarnings(view, player)
+		return game.HandleCompanyEarnings(player, earnings)
+	}
+}
@@ -0,0 +1,81 @@
+package bots_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "bots"
+	"gameState"
+)
+
+// playGame has the bots take every turn of a game until it ends, or until the limit on the number
+// of actions is reached.
+func playGame(t *testing.T, seats map[string]Strategy, limit int) *gameState.Game {
+	names := make([]string, 0, len(seats))
+	for name := range seats {
+		names = append(names, name)
+	}
+	game := gameState.NewGame(names)
+	game.EndConditions.MaxRounds = 5
+
+	for ind := 0; ind < limit && !game.Finished; ind += 1 {
+		actor, err := Actor(game)
+		if err != nil {
+			t.Fatalf("failed to find who acts next: %v", err)
+		}
+		if errs := Play(seats[actor], game); len(errs) > 0 {
+			t.Fatalf("%s's turn %d failed: %v", actor, ind, errs)
+		}
+	}
+	return game
+}
+
+// TestBotGames plays games with every combination of strategies to make sure none of them ever
+// try to take an illegal turn.
+func TestBotGames(t *testing.T) {
+	for _, first := range Names() {
+		for _, second := range Names() {
+			seats := make(map[string]Strategy)
+			for ind, name := range []string{first, second, first} {
+				seat := fmt.Sprintf("%d-%s", ind+1, name)
+				strategy, err := New(name, rand.Int63())
+				if err != nil {
+					t.Fatalf("failed to create %s bot: %v", name, err)
+				}
+				seats[seat] = strategy
+			}
+
+			game := playGame(t, seats, 1000)
+			if len(game.Log) == 0 {
+				t.Errorf("%s and %s bots never took an action", first, second)
+			}
+		}
+	}
+}
+
+// TestGreedyStartsCompanies checks to make sure the greedy bots actually play the game instead of
+// passing every turn.
+func TestGreedyStartsCompanies(t *testing.T) {
+	seats := map[string]Strategy{"1st": Greedy{}, "2nd": Greedy{}, "3rd": Greedy{}}
+	game := playGame(t, seats, 1000)
+
+	started := 0
+	for _, company := range game.Companies {
+		if company.StockPrice > 0 {
+			started += 1
+		}
+	}
+	if started < len(seats) {
+		t.Errorf("%d greedy bots only started %d companies", len(seats), started)
+	}
+	if game.TrainsBought == 0 {
+		t.Error("greedy bots never bought a train")
+	}
+}
+
+func TestUnknownStrategy(t *testing.T) {
+	if _, err := New("not a strategy", 0); err == nil {
+		t.Error("created bot with an unknown strategy")
+	}
+}
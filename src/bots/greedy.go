@@ -0,0 +1,111 @@
+package bots
+
+import (
+	"boardInfo"
+	"gameState"
+)
+
+// Greedy is a strategy that always takes whatever looks best for it right now, without any
+// thought about what the other players might do or what will happen in later rounds.
+type Greedy struct{}
+
+// MarketTurn has the player start a company if they aren't the president of one yet, putting as
+// much money into it as possible. Otherwise the player buys as much stock as they can in the
+// company earning the most for its price, and passes once nothing is earning anything.
+func (Greedy) MarketTurn(game *gameState.Game, player string) gameState.MarketTurn {
+	var result gameState.MarketTurn
+	moves, err := game.LegalMoves()
+	if err != nil {
+		return result
+	}
+
+	presides := false
+	for _, company := range game.Companies {
+		presides = presides || company.President == player
+	}
+
+	var best *gameState.StockOption
+	bestScore := 0.0
+	for ind, option := range moves.Market.Purchases {
+		company := game.Companies[option.Company]
+		score := 0.0
+		if company.StockPrice == 0 && !presides {
+			score = float64(option.Max * option.Price)
+		} else if company.StockPrice != 0 && presides {
+			score = float64(company.NetIncome) / float64(option.Price)
+		}
+		if score > bestScore {
+			best, bestScore = &moves.Market.Purchases[ind], score
+		}
+	}
+
+	if best != nil {
+		result.Purchase = &gameState.MarketAction{
+			Company: best.Company,
+			Count:   best.Max,
+			Price:   best.Price,
+		}
+	}
+	return result
+}
+
+// CompanyInventory buys a train whenever the company has more cities than it can service, then
+// builds track into the city paying the most it can afford. When there is no city in reach it
+// mines coal if it can, and otherwise builds the cheapest track it can to keep expanding.
+func (Greedy) CompanyInventory(game *gameState.Game,
+	player string) gameState.CompanyInventory {
+	var result gameState.CompanyInventory
+	moves, err := game.LegalMoves()
+	if err != nil {
+		return result
+	}
+
+	inventory := moves.Inventory
+	company := game.Companies[moves.Company]
+	board := game.Board()
+	treasury := company.Treasury
+	capacity := 0
+	for ind, count := range company.Equipment {
+		capacity += (ind + 1) * count
+	}
+	if capacity <= len(board.Cities(company.BuiltTrack...)) && inventory.MaxBuy > 0 {
+		result.Buy = 1
+		treasury -= boardInfo.TrainCost(game.TrainsBought + 1)
+	}
+
+	techLevel := boardInfo.TechLevel(game.TrainsBought + result.Buy)
+	best, bestRevenue := "", -1
+	for _, coord := range inventory.Track {
+		cost := board.BuildCost(coord)
+		if cost > treasury {
+			continue
+		}
+		revenue := 0
+		if cities := board.Cities(coord); len(cities) > 0 {
+			revenue = cities[0].Revenue[techLevel-1]
+		}
+		if revenue > bestRevenue ||
+			(revenue == bestRevenue && cost < board.BuildCost(best)) {
+			best, bestRevenue = coord, revenue
+		}
+	}
+
+	if bestRevenue <= 0 && len(inventory.Coal) > 0 {
+		result.Coal = inventory.Coal[0]
+	} else if best != "" {
+		result.Track = []string{best}
+	}
+	return result
+}
+
+// CompanyEarnings lets the game choose the serviced cities, and pays dividends if that leaves the
+// president with a higher net worth than retaining the earnings would.
+func (Greedy) CompanyEarnings(game *gameState.Game, player string) gameState.CompanyEarnings {
+	preview, errs := game.PreviewCompanyEarnings(player, gameState.CompanyEarnings{})
+	if len(errs) > 0 {
+		return gameState.CompanyEarnings{}
+	}
+	dividends := preview.Dividends.Players[player].NetWorth
+	retain := preview.Retain.Players[player].NetWorth
+	return gameState.CompanyEarnings{Dividends: dividends >= retain}
+}
@@ -0,0 +1,20 @@
+package bots
+
+import "gameState"
+
+// Pass is a strategy that never does anything: it passes every market turn, leaves its companies'
+// inventories alone, and lets the game handle their earnings without paying dividends. The server
+// also uses it to take the turns for players who run out of time.
+type Pass struct{}
+
+func (Pass) MarketTurn(game *gameState.Game, player string) gameState.MarketTurn {
+	return gameState.MarketTurn{}
+}
+
+func (Pass) CompanyInventory(game *gameState.Game, player string) gameState.CompanyInventory {
+	return gameState.CompanyInventory{}
+}
+
+func (Pass) CompanyEarnings(game *gameState.Game, player string) gameState.CompanyEarnings {
+	return gameState.CompanyEarnings{}
+}
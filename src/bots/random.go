@@ -0,0 +1,80 @@
+package bots
+
+import (
+	"math/rand"
+
+	"gameState"
+)
+
+// Random is a strategy that picks one of its legal moves at random. It's mostly useful for
+// filling seats in test games, since it makes no effort to win.
+type Random struct {
+	rng *rand.Rand
+}
+
+// MarketTurn trades one random range of stock, or passes. Passing is always one of the options, so
+// the market phase still ends once the bots run out of money.
+func (r *Random) MarketTurn(game *gameState.Game, player string) gameState.MarketTurn {
+	var result gameState.MarketTurn
+	moves, err := game.LegalMoves()
+	if err != nil {
+		return result
+	}
+
+	market := moves.Market
+	options := make([]gameState.StockOption, 0)
+	options = append(options, market.Purchases...)
+	options = append(options, market.Sales...)
+	options = append(options, market.Buybacks...)
+	ind := r.rng.Intn(len(options) + 2)
+	if ind >= len(options) {
+		return result
+	}
+
+	option := options[ind]
+	action := gameState.MarketAction{
+		Company: option.Company,
+		Count:   option.Min + r.rng.Intn(option.Max-option.Min+1),
+		Price:   option.Price,
+	}
+	if ind < len(market.Purchases) {
+		result.Purchase = &action
+	} else if ind < len(market.Purchases)+len(market.Sales) {
+		result.Sales = []gameState.MarketAction{action}
+	} else {
+		result.Buyback = &action
+	}
+	return result
+}
+
+// CompanyInventory does one random thing with the company's inventory: buying equipment,
+// building a single track, mining coal, or nothing at all.
+func (r *Random) CompanyInventory(game *gameState.Game,
+	player string) gameState.CompanyInventory {
+	var result gameState.CompanyInventory
+	moves, err := game.LegalMoves()
+	if err != nil {
+		return result
+	}
+
+	inventory := moves.Inventory
+	switch r.rng.Intn(4) {
+	case 0:
+		result.Buy = r.rng.Intn(inventory.MaxBuy + 1)
+	case 1:
+		if len(inventory.Track) > 0 && inventory.MaxTrack > 0 {
+			result.Track = []string{inventory.Track[r.rng.Intn(len(inventory.Track))]}
+		}
+	case 2:
+		if len(inventory.Coal) > 0 {
+			result.Coal = inventory.Coal[r.rng.Intn(len(inventory.Coal))]
+		}
+	}
+	return result
+}
+
+// CompanyEarnings lets the game choose the serviced cities and flips a coin for dividends.
+func (r *Random) CompanyEarnings(game *gameState.Game,
+	player string) gameState.CompanyEarnings {
+	return gameState.CompanyEarnings{Dividends: r.rng.Intn(2) == 0}
+}
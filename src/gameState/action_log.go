@@ -0,0 +1,93 @@
+package gameState
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Replay creates a new game with the provided players, seed, options, and end conditions and
+// performs every action in the log in order. Since the game makes all of its random decisions
+// using the seed the resulting game will be identical to the one that originally produced the log.
+// An error is returned if the options are invalid, if any of the actions fail, or if the game is
+// not at the time an action was originally taken.
+func Replay(playerNames []string, seed int64, options GameOptions, endConditions EndConditions,
+	log []Action) (*Game, error) {
+	result, errs := NewCustomGame(playerNames, seed, options)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid game options: %v", errs)
+	}
+	result.EndConditions = endConditions
+	if err := result.replay(log); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// replay performs every action in the log on a newly created game.
+func (g *Game) replay(log []Action) error {
+	for ind, action := range log {
+		if err := g.performAction(action); err != nil {
+			return fmt.Errorf("failed to replay action %d: %v", ind, err)
+		}
+	}
+	return nil
+}
+
+// Undo rolls the game back to the state it was in before the most recent action by replaying
+// every action before it. Actions cannot be undone once a new phase has started, since players
+// will already have made decisions based on the order and prices of the new phase.
+func (g *Game) Undo() error {
+	if len(g.Log) == 0 {
+		return fmt.Errorf("no actions to undo")
+	} else if g.Finished {
+		return fmt.Errorf("cannot undo actions after the game is over")
+	}
+	last := g.Log[len(g.Log)-1]
+	if !strings.HasPrefix(last.Time, g.phaseString()+"-") {
+		return fmt.Errorf("cannot undo %s's action from %s after a new phase has started",
+			last.Player, last.Time)
+	}
+
+	result := newGame(sortedPlayerNames(g.Players), g.Seed, g.Options)
+	result.EndConditions = g.EndConditions
+	if err := result.replay(g.Log[:len(g.Log)-1]); err != nil {
+		return err
+	}
+	// The game is back to an earlier state, but it is still a change clients need to see, so the
+	// version keeps counting up instead of going back with the rest of the game.
+	result.Version = g.Version + 1
+	*g = *result
+	return nil
+}
+
+// recordAction adds an action that was just accepted to the log and moves the game on to its next
+// version.
+func (g *Game) recordAction(action Action) {
+	g.Log = append(g.Log, action)
+	g.Version += 1
+}
+
+// performAction performs a single action from the log using the same functions that accepted it
+// in the first place.
+func (g *Game) performAction(action Action) error {
+	if now := g.timeString(); now != action.Time {
+		return fmt.Errorf("action taken at %s, but the game is at %s", action.Time, now)
+	}
+
+	var errs []error
+	switch {
+	case action.Market != nil:
+		errs = g.PerformMarketTurn(action.Player, *action.Market)
+	case action.Inventory != nil:
+		errs = g.UpdateCompanyInventory(action.Player, *action.Inventory)
+	case action.Earnings != nil:
+		errs = g.HandleCompanyEarnings(action.Player, *action.Earnings)
+	default:
+		errs = []error{fmt.Errorf("action at %s has no turn", action.Time)}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s's action at %s failed: %v", action.Player, action.Time, errs)
+	}
+	return nil
+}
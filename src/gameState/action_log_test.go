@@ -0,0 +1,145 @@
+package gameState
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"boardInfo"
+)
+
+// playTurns plays the specified number of turns in the game, choosing what to do for each turn
+// using the provided random source. The turns aren't meant to be good, just legal and varied
+// enough to get the game into lots of different states. Every turn is guaranteed to succeed,
+// since if the random choice fails the player will pass or take the default action instead. No
+// more turns are played once the game is over.
+func playTurns(t *testing.T, game *Game, rng *rand.Rand, count int) {
+	companyNames := make([]string, 0, len(game.Companies))
+	for name := range game.Companies {
+		companyNames = append(companyNames, name)
+	}
+	sort.Strings(companyNames)
+
+	for ind := 0; ind < count && !game.Finished; ind += 1 {
+		var errs []error
+		if game.Phase.Market() {
+			playerName, bought := game.TurnManager.Current(), false
+			if rng.Intn(2) == 0 {
+				company := game.Companies[companyNames[rng.Intn(len(companyNames))]]
+				action := MarketAction{Company: company.Name, Count: 1 + rng.Intn(3)}
+				if company.StockPrice == 0 {
+					action.Count += 1
+					action.Price = boardInfo.StartingStockPrices(game.TechLevel)[rng.Intn(3)]
+				}
+				turn := MarketTurn{Purchase: &action}
+				bought = len(game.PerformMarketTurn(playerName, turn)) == 0
+			}
+			if !bought {
+				errs = game.PerformMarketTurn(playerName, MarketTurn{})
+			}
+		} else if company := game.Companies[game.TurnManager.Current()]; game.Stage != "earnings" {
+			update := CompanyInventory{Buy: rng.Intn(3)}
+			if errs = game.UpdateCompanyInventory(company.President, update); errs != nil {
+				errs = game.UpdateCompanyInventory(company.President, CompanyInventory{})
+			}
+		} else {
+			earnings := CompanyEarnings{Dividends: rng.Intn(2) == 0}
+			errs = game.HandleCompanyEarnings(company.President, earnings)
+		}
+
+		if len(errs) > 0 {
+			t.Fatalf("failed to take turn %d at %s: %v", ind, game.timeString(), errs)
+		}
+	}
+}
+
+// TestReplay checks to make sure replaying a game's log produces an identical game, both with the
+// standard rules and with options and end conditions that change them.
+func TestReplay(t *testing.T) {
+	playerNames := []string{"1st", "2nd", "3rd", "4th"}
+	custom := DefaultGameOptions
+	custom.StartingCash = 2400
+	custom.Companies = []string{"Baltimore & Ohio", "Erie", "Pennsylvania", "Wabash"}
+	custom.BusinessPhases = 1
+	tests := []struct {
+		options       GameOptions
+		endConditions EndConditions
+	}{
+		{DefaultGameOptions, DefaultEndConditions},
+		{custom, DefaultEndConditions},
+		{DefaultGameOptions, EndConditions{MaxRounds: 2}},
+	}
+	for _, test := range tests {
+		seed := rand.Int63()
+		game, errs := NewCustomGame(playerNames, seed, test.options)
+		if len(errs) > 0 {
+			t.Fatalf("failed to create game with %+v: %v", test.options, errs)
+		}
+		game.EndConditions = test.endConditions
+		playTurns(t, game, rand.New(rand.NewSource(seed)), 200)
+
+		replayed, err := Replay(playerNames, seed, test.options, test.endConditions, game.Log)
+		if err != nil {
+			t.Errorf("failed to replay game with %+v and %+v: %v", test.options,
+				test.endConditions, err)
+		} else if !reflect.DeepEqual(replayed, game) {
+			t.Errorf("replayed game doesn't match the original\n\n%+v\n\n%+v", replayed, game)
+		}
+	}
+}
+
+// TestReplayInvalid checks to make sure replaying an invalid log fails instead of silently
+// producing a different game.
+func TestReplayInvalid(t *testing.T) {
+	playerNames := []string{"1st", "2nd", "3rd"}
+	seed := rand.Int63()
+	game := NewSeededGame(playerNames, seed)
+	playTurns(t, game, rand.New(rand.NewSource(seed)), 50)
+
+	replay := func(playerNames []string, options GameOptions, log []Action) error {
+		_, err := Replay(playerNames, seed, options, DefaultEndConditions, log)
+		return err
+	}
+	if err := replay(playerNames, DefaultGameOptions, game.Log[1:]); err == nil {
+		t.Error("replay with a missing action succeeded")
+	}
+	if err := replay(playerNames[1:], DefaultGameOptions, game.Log); err == nil {
+		t.Error("replay with a missing player succeeded")
+	}
+
+	if err := replay(playerNames, GameOptions{}, game.Log); err == nil {
+		t.Error("replay with invalid options succeeded")
+	}
+
+	badLog := append([]Action(nil), game.Log...)
+	badLog[0].Market = nil
+	if err := replay(playerNames, DefaultGameOptions, badLog); err == nil {
+		t.Error("replay with an action without a turn succeeded")
+	}
+}
+
+// TestSeededTurnOrder checks to make sure games with the same seed break ties in the turn order
+// the same way, while different seeds don't always produce the same order.
+func TestSeededTurnOrder(t *testing.T) {
+	playerNames := make([]string, 8)
+	for ind := range playerNames {
+		playerNames[ind] = fmt.Sprintf("%d", ind)
+	}
+
+	seed := rand.Int63()
+	order := NewSeededGame(playerNames, seed).TurnManager.Order
+	other := NewSeededGame(playerNames, seed).TurnManager.Order
+	if !reflect.DeepEqual(order, other) {
+		t.Errorf("games with the same seed have different turn orders: %v != %v", order, other)
+	}
+
+	for inc := int64(1); inc <= 3; inc += 1 {
+		other = NewSeededGame(playerNames, seed+inc).TurnManager.Order
+		if !reflect.DeepEqual(order, other) {
+			return
+		}
+	}
+	t.Errorf("games with different seeds repeatedly produced turn order %v", order)
+}
@@ -1,79 +1,44 @@
 package gameState
 
 import (
-	"fmt"
 	"sort"
 
 	"boardInfo"
 )
 
 func (g *Game) HandleCompanyEarnings(playerName string, earnings CompanyEarnings) []error {
+	if g.Finished {
+		return []error{errGameOver}
+	}
 	if !g.Phase.Business() {
-		return []error{fmt.Errorf("Must be in a business phase to perform business actions")}
+		return []error{ruleError(RuleError{Code: CodeWrongPhase},
+			"Must be in a business phase to perform business actions")}
 	}
 	company := g.Companies[g.TurnManager.Current()]
 	if playerName != company.President {
-		return []error{
-			fmt.Errorf("It's %s's turn and %s is the president", company.Name, company.President)}
+		details := RuleError{Code: CodeNotYourTurn, Player: playerName, Company: company.Name}
+		return []error{ruleError(details, "It's %s's turn and %s is the president", company.Name,
+			company.President)}
 	}
 	if g.Stage != "earnings" {
-		return []error{fmt.Errorf("%s is not ready to handle its earnings", company.Name)}
+		return []error{ruleError(RuleError{Code: CodeWrongStage, Company: company.Name},
+			"%s is not ready to handle its earnings", company.Name)}
 	}
 
 	if len(earnings.Serviced) == 0 {
-		cities := boardInfo.Cities(company.BuiltTrack...)
-		capacity := 0
-		for ind, count := range company.Equipment {
-			capacity += (ind + 1) * count
-		}
-
-		if len(cities) > capacity {
-			boardInfo.SortCities(cities, g.TechLevel)
-			cities = cities[:capacity]
-		}
-
-		earnings.Serviced = make([]string, len(cities))
-		for ind, city := range cities {
-			earnings.Serviced[ind] = city.Location
-		}
+		plan := PlanServicing(g.board, company, g.TechLevel, g.Options.RunningCost)
+		earnings.Serviced = plan.Cities()
 	}
 
 	if errs := g.validateServicedCities(company, earnings); len(errs) > 0 {
 		return errs
 	}
 
-	costs := 0
-	for _, count := range company.Equipment {
-		costs += 10 * g.TechLevel * count
-	}
-
-	gross := 0
-	// The company receives income from mined coal if it has any capital equipment.
-	if costs > 0 {
-		gross += 40 * company.CoalMined
-	}
-	// And the company receives income from every city serviced dependent on tech level.
-	for _, city := range boardInfo.Cities(earnings.Serviced...) {
-		gross += city.Revenue[g.TechLevel-1]
-	}
+	earnings.Serviced = append([]string(nil), earnings.Serviced...)
+	g.recordAction(Action{Time: g.timeString(), Player: playerName, Earnings: &earnings})
 
+	gross, costs := g.companyIncome(company, earnings.Serviced)
 	net := gross - costs
-	defer func(prevPrice int) {
-		company.NetIncome = net
-
-		// If the stock price for this company has changed then update the net worth for
-		// any players that have stock in this company.
-		if company.StockPrice != prevPrice {
-			for _, player := range g.Players {
-				if player.Stocks[company.Name] > 0 {
-					player.NetWorth = player.Cash
-					for name, count := range player.Stocks {
-						player.NetWorth += count * g.Companies[name].StockPrice
-					}
-				}
-			}
-		}
-	}(company.StockPrice)
 
 	if net <= 0 {
 		// A lot happens for unprofitable companies, so it was put into a different function
@@ -95,14 +60,37 @@ func (g *Game) HandleCompanyEarnings(playerName string, earnings CompanyEarnings
 		for _, player := range g.Players {
 			total := perShare * player.Stocks[company.Name]
 			player.Cash += total
-			player.NetWorth += total
 		}
 	}
 
+	company.NetIncome = net
+
+	// The net worth is updated for every player, since the president of a nonprofitable company
+	// can lose their last share in it along with the stock price changing.
+	g.updateNetWorth()
 	g.endBusinessTurn()
 	return nil
 }
 
+// companyIncome calculates the gross income from servicing the cities, and the running costs the
+// company pays for the trains it needs to service them. Trains left idle cost nothing to run.
+func (g *Game) companyIncome(company *Company, serviced []string) (gross, costs int) {
+	costs = g.Options.RunningCost * g.TechLevel * runningTrains(company, len(serviced))
+
+	// The company receives income from mined coal if it has any capital equipment.
+	for _, count := range company.Equipment {
+		if count > 0 {
+			gross += g.Options.CoalValue * company.CoalMined
+			break
+		}
+	}
+	// And the company receives income from every city serviced dependent on tech level.
+	for _, city := range g.board.Cities(serviced...) {
+		gross += city.Revenue[g.TechLevel-1]
+	}
+	return gross, costs
+}
+
 // validateServicedCities makes sure the company has the capability of servicing all the cities
 // the president indicated should be covered. It realistically doesn't need to be attached to
 // the game object, but since it is basically alone in this regard we keep things consistent.
@@ -116,13 +104,17 @@ func (g *Game) validateServicedCities(company *Company, earnings CompanyEarnings
 		capacity += (ind + 1) * count
 	}
 	if len(earnings.Serviced) > capacity {
-		errs = append(errs, fmt.Errorf("%s can only service %d cities", company.Name, capacity))
+		details := RuleError{Code: CodeServiceCapacity, Company: company.Name,
+			Amount: len(earnings.Serviced), Limit: capacity}
+		errs = append(errs, ruleError(details, "%s can only service %d cities", company.Name,
+			capacity))
 	}
 
 	// Make sure every coordinate provided actually has a city on it.
-	cities := boardInfo.Cities(earnings.Serviced...)
+	cities := g.board.Cities(earnings.Serviced...)
 	if len(cities) != len(earnings.Serviced) {
-		errs = append(errs, fmt.Errorf("not all map locations provided contain cities"))
+		errs = append(errs, ruleError(RuleError{Code: CodeNotACity, Company: company.Name},
+			"not all map locations provided contain cities"))
 	}
 
 	// Then make sure the company isn't trying to service any cities that it doesn't have
@@ -130,8 +122,10 @@ func (g *Game) validateServicedCities(company *Company, earnings CompanyEarnings
 	for _, city := range cities {
 		ind := sort.SearchStrings(company.BuiltTrack, city.Location)
 		if ind < 0 || ind >= len(company.BuiltTrack) || company.BuiltTrack[ind] != city.Location {
-			errs = append(errs, fmt.Errorf("%s is not present in %s to be able to service it",
-				company.Name, city.Name))
+			details := RuleError{Code: CodeCityNotReached, Company: company.Name,
+				Hex: city.Location}
+			errs = append(errs, ruleError(details,
+				"%s is not present in %s to be able to service it", company.Name, city.Name))
 		}
 	}
 
@@ -154,9 +148,9 @@ func (g *Game) handleNonprofitable(company *Company) {
 
 	// After checking for a new president, if no one holds any stock in this company it enters
 	// receivership, losing its treasury and all capital equipment, recovering its orphaned
-	// stock, and setting its stock price at 50.
-	for _, player := range g.Players {
-		if player.Stocks[company.Name] > president.Stocks[company.Name] {
+	// stock, and setting its stock price to the receivership price.
+	for _, name := range sortedPlayerNames(g.Players) {
+		if player := g.Players[name]; player.Stocks[company.Name] > president.Stocks[company.Name] {
 			president = player
 		}
 	}
@@ -172,5 +166,16 @@ func (g *Game) handleNonprofitable(company *Company) {
 	for ind := range company.Equipment {
 		company.Equipment[ind] = 0
 	}
-	company.StockPrice = 50
+	company.StockPrice = g.Options.ReceivershipPrice
+}
+
+// updateNetWorth recalculates every player's net worth from their cash and the current stock
+// prices. It needs to be called whenever a stock price changes.
+func (g *Game) updateNetWorth() {
+	for _, player := range g.Players {
+		player.NetWorth = player.Cash
+		for name, count := range player.Stocks {
+			player.NetWorth += count * g.Companies[name].StockPrice
+		}
+	}
 }
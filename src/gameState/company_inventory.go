@@ -1,8 +1,8 @@
 package gameState
 
 import (
-	"fmt"
 	"sort"
+	"strings"
 
 	"boardInfo"
 )
@@ -17,18 +17,23 @@ func stringInSlice(value string, slice []string) bool {
 }
 
 func (g *Game) UpdateCompanyInventory(playerName string, update CompanyInventory) []error {
+	if g.Finished {
+		return []error{errGameOver}
+	}
 	if !g.Phase.Business() {
-		return []error{fmt.Errorf("Must be in a business phase to perform business actions")}
+		return []error{ruleError(RuleError{Code: CodeWrongPhase},
+			"Must be in a business phase to perform business actions")}
 	}
 	company := g.Companies[g.TurnManager.Current()]
 
 	if playerName != company.President {
-		return []error{
-			fmt.Errorf("It's %s's turn and %s is the president", company.Name, company.President),
-		}
+		details := RuleError{Code: CodeNotYourTurn, Player: playerName, Company: company.Name}
+		return []error{ruleError(details, "It's %s's turn and %s is the president", company.Name,
+			company.President)}
 	}
 	if g.Stage != "inventory" {
-		return []error{fmt.Errorf("%s has already updated its inventory", company.Name)}
+		return []error{ruleError(RuleError{Code: CodeWrongStage, Company: company.Name},
+			"%s has already updated its inventory", company.Name)}
 	}
 
 	var errs []error
@@ -39,6 +44,9 @@ func (g *Game) UpdateCompanyInventory(playerName string, update CompanyInventory
 		return errs
 	}
 
+	update.Track = append([]string(nil), update.Track...)
+	g.recordAction(Action{Time: g.timeString(), Player: playerName, Inventory: &update})
+
 	for ind, count := range update.Scrap {
 		techLvl := ind + 1
 		company.Treasury += 20 * techLvl * count
@@ -59,7 +67,7 @@ func (g *Game) UpdateCompanyInventory(playerName string, update CompanyInventory
 		}
 	}
 	for _, hexCoord := range update.Track {
-		company.Treasury -= boardInfo.BuildCost(hexCoord)
+		company.Treasury -= g.board.BuildCost(hexCoord)
 	}
 	company.BuiltTrack = append(company.BuiltTrack, update.Track...)
 	company.UnbuiltTrack -= len(update.Track)
@@ -76,28 +84,40 @@ func (g *Game) validateBusinessExpense(company *Company, update CompanyInventory
 	for ind, count := range update.Scrap {
 		techLvl := ind + 1
 		if count > company.Equipment[ind] {
+			details := RuleError{Code: CodeInsufficientEquipment, Company: company.Name,
+				Amount: count, Limit: company.Equipment[ind]}
+			errs = append(errs, ruleError(details,
+				"%s only has %d tech level %d equipment to scrap", company.Name,
+				company.Equipment[ind], techLvl))
 			count = company.Equipment[ind]
-			errs = append(errs, fmt.Errorf("%s only has %d tech level %d equipment to scrap",
-				company.Name, company.Equipment[ind], techLvl))
 		}
 		availableMoney += 20 * techLvl * count
 	}
 
+	if remaining := boardInfo.TotalTrains - g.TrainsBought; update.Buy > remaining {
+		details := RuleError{Code: CodeNoTrainsLeft, Company: company.Name, Amount: update.Buy,
+			Limit: remaining}
+		errs = append(errs, ruleError(details, "only %d trains are left to buy", remaining))
+		update.Buy = remaining
+	}
 	equipCost := 0
 	for ind := 1; ind <= update.Buy; ind += 1 {
 		equipCost += boardInfo.TrainCost(g.TrainsBought + ind)
 	}
 	trackCost := 0
 	for _, hexCoord := range update.Track {
-		if cost := boardInfo.BuildCost(hexCoord); cost > 0 {
+		if cost := g.board.BuildCost(hexCoord); cost > 0 {
 			trackCost += cost
 		} else {
-			errs = append(errs, fmt.Errorf("Invalid hex coordinate %q to build track", hexCoord))
+			errs = append(errs, ruleError(RuleError{Code: CodeInvalidHex, Hex: hexCoord},
+				"Invalid hex coordinate %q to build track", hexCoord))
 		}
 	}
 
 	if equipCost+trackCost > availableMoney {
-		errs = append(errs, fmt.Errorf("insufficient money to perform all action"))
+		details := RuleError{Code: CodeInsufficientTreasury, Company: company.Name,
+			Amount: equipCost + trackCost, Limit: availableMoney}
+		errs = append(errs, ruleError(details, "insufficient money to perform all action"))
 	}
 	return errs
 }
@@ -106,31 +126,41 @@ func (g *Game) validateBuildLimits(company *Company, update CompanyInventory) []
 	var errs []error
 
 	if len(update.Track) > 0 && update.Coal != "" {
-		errs = append(errs, fmt.Errorf("cannot build track and mine coal on the same turn"))
+		details := RuleError{Code: CodeConflictingActions, Company: company.Name}
+		errs = append(errs, ruleError(details, "cannot build track and mine coal on the same turn"))
 	}
 	if update.Coal != "" {
 		if !stringInSlice(update.Coal, g.UnminedCoal) {
-			errs = append(errs, fmt.Errorf("no coal located at %q to be mined", update.Coal))
+			errs = append(errs, ruleError(RuleError{Code: CodeNoCoal, Hex: update.Coal},
+				"no coal located at %q to be mined", update.Coal))
 		}
 		if !stringInSlice(update.Coal, company.BuiltTrack) {
-			errs = append(errs, fmt.Errorf("%s has no track on %q to allow mining",
+			details := RuleError{Code: CodeNoTrack, Company: company.Name, Hex: update.Coal}
+			errs = append(errs, ruleError(details, "%s has no track on %q to allow mining",
 				company.Name, update.Coal))
 		}
 	}
 	if len(update.Track) > company.UnbuiltTrack {
-		errs = append(errs, fmt.Errorf("%s only has %d unbuilt tracks remaining",
+		details := RuleError{Code: CodeNoTrackLeft, Company: company.Name,
+			Amount: len(update.Track), Limit: company.UnbuiltTrack}
+		errs = append(errs, ruleError(details, "%s only has %d unbuilt tracks remaining",
 			company.Name, company.UnbuiltTrack))
 	}
 	if techLvl := boardInfo.TechLevel(g.TrainsBought + update.Buy); len(update.Track) > techLvl {
-		errs = append(errs, fmt.Errorf("cannot build more than %d track per turn", techLvl))
+		details := RuleError{Code: CodeBuildLimit, Company: company.Name, Amount: len(update.Track),
+			Limit: techLvl}
+		errs = append(errs, ruleError(details, "cannot build more than %d track per turn", techLvl))
 	}
 	for _, coord := range update.Track {
 		if stringInSlice(coord, company.BuiltTrack) {
-			errs = append(errs, fmt.Errorf("%s already has track built on %q", company.Name, coord))
+			details := RuleError{Code: CodeTrackAlreadyBuilt, Company: company.Name, Hex: coord}
+			errs = append(errs, ruleError(details, "%s already has track built on %q",
+				company.Name, coord))
 		}
 	}
-	if !boardInfo.TilesContiguous(company.BuiltTrack, update.Track) {
-		errs = append(errs, fmt.Errorf("all built track must be connected"))
+	if !g.board.TilesContiguous(company.BuiltTrack, update.Track) {
+		details := RuleError{Code: CodeTrackNotContiguous, Company: company.Name}
+		errs = append(errs, ruleError(details, "all built track must be connected"))
 	}
 
 	return errs
@@ -146,8 +176,34 @@ func (g *Game) validateCityRestrictions(company *Company, update CompanyInventor
 		cityCapacity = 2
 	}
 
-	for _, city := range boardInfo.Cities(update.Track...) {
-		// TODO: make sure no company builds in Pennsylvania's cities before it does.
+	// Pennsylvania and New York Central have to build in all of their home cities before they can
+	// build in any other city. Building in the last of them on the same turn is allowed.
+	homeCities := g.board.HomeCities(company.Name)
+	homeFinished := true
+	for _, coord := range homeCities {
+		if !stringInSlice(coord, company.BuiltTrack) && !stringInSlice(coord, update.Track) {
+			homeFinished = false
+		}
+	}
+
+	pennsylvania := g.Companies["Pennsylvania"]
+	for _, city := range g.board.Cities(update.Track...) {
+		// No other company can build in Pennsylvania's home cities until Pennsylvania has, unless
+		// Pennsylvania isn't part of the game.
+		if pennsylvania != nil && city.Exception == pennsylvania.Name && company != pennsylvania &&
+			!stringInSlice(city.Location, pennsylvania.BuiltTrack) {
+			details := RuleError{Code: CodeHomeCityRestricted, Company: company.Name,
+				Hex: city.Location}
+			errs = append(errs, ruleError(details, "%s cannot build in %s until %s has built there",
+				company.Name, city.Name, pennsylvania.Name))
+		}
+		if !homeFinished && city.Exception != company.Name {
+			details := RuleError{Code: CodeHomeCityRestricted, Company: company.Name,
+				Hex: city.Location}
+			errs = append(errs, ruleError(details,
+				"%s must build in all of %s before building in %s", company.Name,
+				g.cityNames(homeCities), city.Name))
+		}
 
 		// Check to make sure there is still enough space in the city for another railroad.
 		if city.Exception != "universal" {
@@ -158,13 +214,23 @@ func (g *Game) validateCityRestrictions(company *Company, update CompanyInventor
 				}
 			}
 			if existent >= cityCapacity {
-				errs = append(errs, fmt.Errorf("%s already has %d railroads", city.Name, existent))
+				details := RuleError{Code: CodeCityFull, Company: company.Name, Hex: city.Location,
+					Amount: existent + 1, Limit: cityCapacity}
+				errs = append(errs, ruleError(details, "%s already has %d railroads", city.Name,
+					existent))
 			}
 		}
 	}
 
-	// TODO: make sure Pennsylvania and New York Central build in all their required cities
-	// before they build in any others.
-
 	return errs
 }
+
+// cityNames lists the names of the cities on the provided coordinates for error messages.
+func (g *Game) cityNames(coords []string) string {
+	cities := g.board.Cities(coords...)
+	names := make([]string, len(cities))
+	for ind, city := range cities {
+		names[ind] = city.Name
+	}
+	return strings.Join(names, ", ")
+}
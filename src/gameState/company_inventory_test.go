@@ -0,0 +1,104 @@
+package gameState
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestHomeCityRestrictions checks the rules for building in the home cities of Pennsylvania and
+// New York Central. No one can build in Pennsylvania's cities before it does, and both companies
+// have to build in all of their own cities before they can build in any others.
+func TestHomeCityRestrictions(t *testing.T) {
+	tests := []struct {
+		name     string
+		company  string
+		built    map[string][]string
+		track    []string
+		errorMsg string
+	}{
+		{
+			name:     "other company before Pennsylvania",
+			company:  "Baltimore & Ohio",
+			built:    map[string][]string{"Pennsylvania": {"G24"}},
+			track:    []string{"G20"},
+			errorMsg: "cannot build in Harrisburg until Pennsylvania has built there",
+		},
+		{
+			name:    "other company after Pennsylvania",
+			company: "Baltimore & Ohio",
+			built:   map[string][]string{"Pennsylvania": {"G24", "G22", "G20"}},
+			track:   []string{"G20"},
+		},
+		{
+			name:    "Pennsylvania in its own city",
+			company: "Pennsylvania",
+			built:   map[string][]string{"Pennsylvania": {"G24", "G22"}},
+			track:   []string{"G20"},
+		},
+		{
+			name:    "Pennsylvania outside its cities",
+			company: "Pennsylvania",
+			built:   map[string][]string{"Pennsylvania": {"G24"}},
+			track:   []string{"H23"},
+			errorMsg: "Pennsylvania must build in all of Pittsburgh, Harrisburg, Philadelphia " +
+				"before building in Baltimore",
+		},
+		{
+			name:    "Pennsylvania between its cities",
+			company: "Pennsylvania",
+			built:   map[string][]string{"Pennsylvania": {"G24"}},
+			track:   []string{"G22"},
+		},
+		{
+			name:    "Pennsylvania finishing its cities",
+			company: "Pennsylvania",
+			built:   map[string][]string{"Pennsylvania": {"G16", "G18", "G24", "G22"}},
+			track:   []string{"G20", "H21", "H23"},
+		},
+		{
+			name:    "Pennsylvania after its cities",
+			company: "Pennsylvania",
+			built:   map[string][]string{"Pennsylvania": {"G16", "G18", "G20", "G22", "G24"}},
+			track:   []string{"H23"},
+		},
+		{
+			name:     "New York Central outside its cities",
+			company:  "New York Central",
+			built:    map[string][]string{"New York Central": {"D25", "D23", "D21", "D19"}},
+			track:    []string{"D29"},
+			errorMsg: "New York Central must build in all of",
+		},
+		{
+			name:    "New York Central after its cities",
+			company: "New York Central",
+			built: map[string][]string{
+				"New York Central": {"D19", "D21", "D23", "D25", "E26", "F25"},
+			},
+			track: []string{"D29"},
+		},
+		{
+			name:     "Pennsylvania city before Pennsylvania starts",
+			company:  "Boston & Maine",
+			built:    map[string][]string{},
+			track:    []string{"G24"},
+			errorMsg: "cannot build in Philadelphia until Pennsylvania has built there",
+		},
+	}
+
+	for _, test := range tests {
+		game := NewGame([]string{"1st", "2nd", "3rd"})
+		for name, built := range test.built {
+			game.Companies[name].BuiltTrack = built
+		}
+		update := CompanyInventory{Track: test.track}
+
+		errs := game.validateCityRestrictions(game.Companies[test.company], update)
+		if test.errorMsg == "" && len(errs) > 0 {
+			t.Errorf("%s: unexpected errors %v", test.name, errs)
+		} else if test.errorMsg != "" && len(errs) != 1 {
+			t.Errorf("%s: expected one error, got %v", test.name, errs)
+		} else if test.errorMsg != "" && !strings.Contains(errs[0].Error(), test.errorMsg) {
+			t.Errorf("%s: error %q doesn't contain %q", test.name, errs[0], test.errorMsg)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gameState
+
+// The TurnProjection struct shows what a turn would change if it was taken. Only the players and
+// companies whose money would change are included, along with what they would have afterwards.
+type TurnProjection struct {
+	TechLevel    int            `json:"tech_level"`
+	TrainsBought int            `json:"trains_bought"`
+	Cash         map[string]int `json:"cash"`
+	Treasury     map[string]int `json:"treasury"`
+}
+
+// ValidateMarketTurn checks the market turn without performing it. It returns every error that
+// PerformMarketTurn would, or the projected effects of the turn if it is valid.
+func (g *Game) ValidateMarketTurn(playerName string, turn MarketTurn) (*TurnProjection, []error) {
+	after, err := g.Copy()
+	if err != nil {
+		return nil, []error{err}
+	} else if errs := after.PerformMarketTurn(playerName, turn); len(errs) > 0 {
+		return nil, errs
+	}
+	return g.projectTurn(after), nil
+}
+
+// ValidateCompanyInventory checks the inventory update without performing it. It returns every
+// error that UpdateCompanyInventory would, or the projected effects of the update if it is valid.
+func (g *Game) ValidateCompanyInventory(playerName string,
+	update CompanyInventory) (*TurnProjection, []error) {
+	after, err := g.Copy()
+	if err != nil {
+		return nil, []error{err}
+	} else if errs := after.UpdateCompanyInventory(playerName, update); len(errs) > 0 {
+		return nil, errs
+	}
+	return g.projectTurn(after), nil
+}
+
+// projectTurn compares the game after a turn was taken to the current game.
+func (g *Game) projectTurn(after *Game) *TurnProjection {
+	result := &TurnProjection{
+		TechLevel:    after.TechLevel,
+		TrainsBought: after.TrainsBought,
+		Cash:         make(map[string]int),
+		Treasury:     make(map[string]int),
+	}
+	for name, player := range after.Players {
+		if player.Cash != g.Players[name].Cash {
+			result.Cash[name] = player.Cash
+		}
+	}
+	for name, company := range after.Companies {
+		if company.Treasury != g.Companies[name].Treasury {
+			result.Treasury[name] = company.Treasury
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package gameState
+
+import (
+	"reflect"
+	"testing"
+
+	"boardInfo"
+)
+
+// TestValidateMarketTurn checks to make sure validating a market turn reports all of its errors
+// and the projected cash and treasury without changing the game.
+func TestValidateMarketTurn(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	player := game.TurnManager.Current()
+	company := randomCompany(false)
+	price := startingPrices[0][1]
+	before := copyGame(game)
+
+	purchase := MarketAction{Company: company, Count: 2, Price: price}
+	projection, errs := game.ValidateMarketTurn(player, MarketTurn{Purchase: &purchase})
+	if len(errs) > 0 {
+		t.Fatalf("failed to validate purchase: %v", errs)
+	} else if !reflect.DeepEqual(before, game) {
+		t.Fatal("validating a market turn changed the game")
+	}
+	expected := &TurnProjection{
+		TechLevel:    1,
+		TrainsBought: 0,
+		Cash:         map[string]int{player: game.Players[player].Cash - 2*price},
+		Treasury:     map[string]int{company: 2 * price},
+	}
+	if !reflect.DeepEqual(projection, expected) {
+		t.Errorf("purchase projection is %+v, expected %+v", projection, expected)
+	}
+
+	// Every problem with the turn should be reported at once.
+	purchase = MarketAction{Company: company, Count: 10, Price: price}
+	sale := MarketAction{Company: randomCompany(true), Count: 1}
+	turn := MarketTurn{Sales: []MarketAction{sale}, Purchase: &purchase}
+	if _, errs := game.ValidateMarketTurn(player, turn); len(errs) != 2 {
+		t.Errorf("invalid sale and purchase returned errors %v, expected 2", errs)
+	} else if !reflect.DeepEqual(before, game) {
+		t.Error("validating an invalid market turn changed the game")
+	}
+}
+
+// TestValidateCompanyInventory checks to make sure validating an inventory update reports all of
+// its errors and the projected treasury and tech level without changing the game.
+func TestValidateCompanyInventory(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 2, startingPrices[0][2]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+	president := game.Companies[company].President
+	treasury := game.Companies[company].Treasury
+	before := copyGame(game)
+
+	update := CompanyInventory{Buy: 1}
+	projection, errs := game.ValidateCompanyInventory(president, update)
+	if len(errs) > 0 {
+		t.Fatalf("failed to validate inventory update: %v", errs)
+	} else if !reflect.DeepEqual(before, game) {
+		t.Fatal("validating an inventory update changed the game")
+	}
+	expected := &TurnProjection{
+		TechLevel:    1,
+		TrainsBought: 1,
+		Cash:         map[string]int{},
+		Treasury:     map[string]int{company: treasury - boardInfo.TrainCost(1)},
+	}
+	if !reflect.DeepEqual(projection, expected) {
+		t.Errorf("inventory projection is %+v, expected %+v", projection, expected)
+	}
+
+	// Every problem with the update should be reported at once.
+	update = CompanyInventory{Buy: 20, Track: []string{"not a hex"}, Coal: "G18"}
+	if _, errs := game.ValidateCompanyInventory(president, update); len(errs) < 4 {
+		t.Errorf("invalid inventory update returned errors %v, expected at least 4", errs)
+	} else if !reflect.DeepEqual(before, game) {
+		t.Error("validating an invalid inventory update changed the game")
+	}
+}
@@ -0,0 +1,132 @@
+package gameState
+
+import (
+	"fmt"
+
+	"util"
+)
+
+// The EarningsPreview struct shows the president what would happen to the company and the players
+// for each of the choices they have when handling the company's earnings. The serviced cities are
+// the ones that would be used, which are chosen automatically if the president didn't list any.
+type EarningsPreview struct {
+	Serviced  []string        `json:"serviced_cities"`
+	Dividends EarningsOutcome `json:"dividends"`
+	Retain    EarningsOutcome `json:"retain"`
+}
+
+// The EarningsOutcome struct describes the result of handling the company's earnings one way.
+// The company and player changes are relative to the current game, and only the players that
+// would be affected are included.
+type EarningsOutcome struct {
+	Gross         int  `json:"gross"`
+	Costs         int  `json:"costs"`
+	Net           int  `json:"net"`
+	PerShare      int  `json:"dividend_per_share"`
+	Nonprofitable bool `json:"nonprofitable"`
+	Receivership  bool `json:"receivership"`
+
+	Company CompanyDelta           `json:"company"`
+	Players map[string]PlayerDelta `json:"players"`
+}
+
+// The CompanyDelta struct holds the changes to a company. The president is only set if it would
+// change to someone else.
+type CompanyDelta struct {
+	StockPrice int    `json:"stock_price"`
+	Treasury   int    `json:"treasury"`
+	HeldStock  int    `json:"held_stock"`
+	President  string `json:"president,omitempty"`
+}
+
+// The PlayerDelta struct holds the changes to a player's cash, net worth and stock in the company.
+type PlayerDelta struct {
+	Cash     int `json:"cash"`
+	NetWorth int `json:"net_worth"`
+	Stock    int `json:"stock"`
+}
+
+// PreviewCompanyEarnings shows what would happen if the player handled the current company's
+// earnings both with and without paying dividends. It accepts the same input as
+// HandleCompanyEarnings and fails for the same reasons, but never changes the game.
+func (g *Game) PreviewCompanyEarnings(playerName string,
+	earnings CompanyEarnings) (*EarningsPreview, []error) {
+	result := new(EarningsPreview)
+
+	for _, dividends := range []bool{true, false} {
+		after, err := g.Copy()
+		if err != nil {
+			return nil, []error{err}
+		}
+		company := g.Companies[g.TurnManager.Current()]
+		earnings.Dividends = dividends
+		if errs := after.HandleCompanyEarnings(playerName, earnings); len(errs) > 0 {
+			return nil, errs
+		}
+		result.Serviced = after.Log[len(after.Log)-1].Earnings.Serviced
+
+		outcome := g.earningsOutcome(after, company, result.Serviced)
+		if dividends {
+			if outcome.Net > 0 {
+				outcome.PerShare = outcome.Net / 10
+			}
+			result.Dividends = outcome
+		} else {
+			result.Retain = outcome
+		}
+	}
+	return result, nil
+}
+
+// Copy creates a completely separate copy of the game, so actions can be performed on the copy
+// without affecting the original.
+//
+// Actions are never changed once they are in the log, so the copy shares the log instead of
+// copying every action again. The copy's log has its capacity limited to its length, which makes
+// sure appending to either log never writes over the other. Boards never change either, so the
+// copy shares the board.
+func (g *Game) Copy() (*Game, error) {
+	shallow := *g
+	shallow.Log = nil
+	iface, err := util.Copy(&shallow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy game: %v", err)
+	}
+	result := iface.(*Game)
+	result.Log = g.Log[:len(g.Log):len(g.Log)]
+	result.board = g.board
+	return result, nil
+}
+
+// earningsOutcome compares the game after the company handled its earnings to the current game.
+func (g *Game) earningsOutcome(after *Game, company *Company, serviced []string) EarningsOutcome {
+	var result EarningsOutcome
+	result.Gross, result.Costs = g.companyIncome(company, serviced)
+	result.Net = result.Gross - result.Costs
+	result.Nonprofitable = result.Net <= 0
+
+	changed := after.Companies[company.Name]
+	result.Receivership = changed.President == ""
+	result.Company = CompanyDelta{
+		StockPrice: changed.StockPrice - company.StockPrice,
+		Treasury:   changed.Treasury - company.Treasury,
+		HeldStock:  changed.HeldStock - company.HeldStock,
+	}
+	if changed.President != company.President {
+		result.Company.President = changed.President
+	}
+
+	result.Players = make(map[string]PlayerDelta)
+	for name, player := range g.Players {
+		other := after.Players[name]
+		delta := PlayerDelta{
+			Cash:     other.Cash - player.Cash,
+			NetWorth: other.NetWorth - player.NetWorth,
+			Stock:    other.Stocks[company.Name] - player.Stocks[company.Name],
+		}
+		if delta != (PlayerDelta{}) {
+			result.Players[name] = delta
+		}
+	}
+	return result
+}
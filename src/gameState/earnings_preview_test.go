@@ -0,0 +1,89 @@
+package gameState
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEarningsPreview checks to make sure the preview matches what actually happens for both of
+// the president's options, without changing the game.
+func TestEarningsPreview(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	company := startEarnings(t, game, CompanyInventory{Buy: 1})
+	president := game.Companies[company].President
+
+	before := copyGame(game)
+	preview, errs := game.PreviewCompanyEarnings(president, CompanyEarnings{})
+	if len(errs) > 0 {
+		t.Fatalf("failed to preview %s's earnings: %v", company, errs)
+	} else if !reflect.DeepEqual(before, game) {
+		t.Fatal("previewing earnings changed the game")
+	}
+	plan := PlanServicing(game.Board(), game.Companies[company], game.TechLevel,
+		game.Options.RunningCost)
+	if !reflect.DeepEqual(preview.Serviced, plan.Cities()) {
+		t.Errorf("preview services %v, expected %v", preview.Serviced, plan.Cities())
+	}
+
+	for _, dividends := range []bool{true, false} {
+		outcome := preview.Retain
+		if dividends {
+			outcome = preview.Dividends
+		}
+		if outcome.Net != outcome.Gross-outcome.Costs || outcome.Nonprofitable {
+			t.Errorf("%s with one train has outcome %+v", company, outcome)
+		}
+
+		after := copyGame(game)
+		earnings := CompanyEarnings{Dividends: dividends}
+		if errs := after.HandleCompanyEarnings(president, earnings); len(errs) > 0 {
+			t.Fatalf("failed to handle %s's earnings: %v", company, errs)
+		}
+		actual := game.earningsOutcome(after, game.Companies[company], preview.Serviced)
+		if outcome.Company != actual.Company ||
+			!reflect.DeepEqual(outcome.Players, actual.Players) {
+			t.Errorf("preview with dividends=%v is %+v, actual outcome %+v", dividends,
+				outcome, actual)
+		}
+	}
+
+	perShare := preview.Dividends.Net / 10
+	if preview.Dividends.PerShare != perShare || preview.Retain.PerShare != 0 {
+		t.Errorf("dividends per share are $%d and $%d, expected $%d and $0",
+			preview.Dividends.PerShare, preview.Retain.PerShare, perShare)
+	}
+	if cash := preview.Dividends.Players[president].Cash; cash != 2*perShare {
+		t.Errorf("president gets $%d in dividends for 2 shares at $%d", cash, perShare)
+	}
+	if preview.Retain.Company.Treasury != preview.Retain.Net || len(preview.Retain.Players) > 0 {
+		t.Errorf("retaining earnings has outcome %+v", preview.Retain)
+	}
+
+	if _, errs := game.PreviewCompanyEarnings("not the president", CompanyEarnings{}); errs == nil {
+		t.Error("preview for a player who isn't the president succeeded")
+	}
+}
+
+// TestEarningsPreviewNonprofitable checks to make sure the preview shows when a company won't
+// make a profit, along with the stock the president will lose.
+func TestEarningsPreviewNonprofitable(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	company := startEarnings(t, game, CompanyInventory{})
+	president := game.Companies[company].President
+
+	preview, errs := game.PreviewCompanyEarnings(president, CompanyEarnings{})
+	if len(errs) > 0 {
+		t.Fatalf("failed to preview %s's earnings: %v", company, errs)
+	}
+	for _, outcome := range []EarningsOutcome{preview.Dividends, preview.Retain} {
+		if !outcome.Nonprofitable || outcome.PerShare != 0 {
+			t.Errorf("%s without equipment has outcome %+v", company, outcome)
+		}
+		if outcome.Company.StockPrice >= 0 || outcome.Company.HeldStock != 1 {
+			t.Errorf("nonprofitable company has changes %+v", outcome.Company)
+		}
+		if stock := outcome.Players[president].Stock; stock != -1 {
+			t.Errorf("nonprofitable company's president changes %d stock, expected -1", stock)
+		}
+	}
+}
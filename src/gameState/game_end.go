@@ -0,0 +1,104 @@
+package gameState
+
+import (
+	"fmt"
+	"sort"
+
+	"boardInfo"
+)
+
+// The EndConditions struct determines when the game is over. The conditions are only checked at
+// the end of each round, so once any of them have been met the game will end as soon as the
+// current round is finished. A MaxRounds of zero means there is no limit on the number of rounds.
+type EndConditions struct {
+	MaxRounds     int  `json:"max_rounds,omitempty"`
+	LastTrain     bool `json:"last_train"`
+	TopStockPrice bool `json:"top_stock_price"`
+}
+
+// DefaultEndConditions are the conditions used for new games unless they are changed.
+var DefaultEndConditions = EndConditions{LastTrain: true, TopStockPrice: true}
+
+// The Standing struct holds a player's final result once the game is over. Players with the same
+// net worth and cash share the same rank.
+type Standing struct {
+	Player     string `json:"player"`
+	Rank       int    `json:"rank"`
+	Cash       int    `json:"cash"`
+	StockValue int    `json:"stock_value"`
+	NetWorth   int    `json:"net_worth"`
+}
+
+// errGameOver is returned for any turn attempted after the game has ended.
+var errGameOver = ruleError(RuleError{Code: CodeGameOver},
+	"The game is over, no more turns can be taken")
+
+// endReason checks the end conditions, returning why the game should end or an empty string if
+// the game should continue.
+func (g *Game) endReason() string {
+	if g.EndConditions.MaxRounds > 0 && g.Round >= g.EndConditions.MaxRounds {
+		return fmt.Sprintf("played all %d rounds", g.EndConditions.MaxRounds)
+	}
+	if g.EndConditions.LastTrain && g.TrainsBought >= boardInfo.TotalTrains {
+		return "the last train was bought"
+	}
+	if g.EndConditions.TopStockPrice {
+		for _, name := range g.sortedCompanyNames() {
+			if g.Companies[name].StockPrice >= boardInfo.MaxStockPrice() {
+				return fmt.Sprintf("%s reached the top stock price", name)
+			}
+		}
+	}
+	return ""
+}
+
+// sortedCompanyNames returns the names of all the companies in alphabetical order.
+func (g *Game) sortedCompanyNames() []string {
+	result := make([]string, 0, len(g.Companies))
+	for name := range g.Companies {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// finishGame ends the game, leaving no one with a turn, and calculates the final standings.
+func (g *Game) finishGame(reason string) {
+	g.Finished = true
+	g.EndReason = reason
+	g.Stage = ""
+	g.TurnManager.Order = nil
+	g.TurnManager.Number = 0
+	g.TurnManager.Passes = 0
+
+	g.Standings = make([]Standing, 0, len(g.Players))
+	for _, name := range sortedPlayerNames(g.Players) {
+		player := g.Players[name]
+		standing := Standing{Player: name, Cash: player.Cash}
+		for company, count := range player.Stocks {
+			standing.StockValue += count * g.Companies[company].StockPrice
+		}
+		standing.NetWorth = standing.Cash + standing.StockValue
+		player.NetWorth = standing.NetWorth
+		g.Standings = append(g.Standings, standing)
+	}
+
+	// The names are already sorted, so a stable sort leaves players that are completely tied in
+	// alphabetical order.
+	sort.SliceStable(g.Standings, func(i, j int) bool {
+		if g.Standings[i].NetWorth != g.Standings[j].NetWorth {
+			return g.Standings[i].NetWorth > g.Standings[j].NetWorth
+		}
+		return g.Standings[i].Cash > g.Standings[j].Cash
+	})
+	for ind := range g.Standings {
+		g.Standings[ind].Rank = ind + 1
+		if ind == 0 {
+			continue
+		}
+		prev := g.Standings[ind-1]
+		if prev.NetWorth == g.Standings[ind].NetWorth && prev.Cash == g.Standings[ind].Cash {
+			g.Standings[ind].Rank = prev.Rank
+		}
+	}
+}
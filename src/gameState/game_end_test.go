@@ -0,0 +1,128 @@
+package gameState
+
+import (
+	"testing"
+
+	"boardInfo"
+)
+
+// passRound has every player pass until the next round starts or the game is over.
+func passRound(t *testing.T, game *Game) {
+	round := game.Round
+	for game.Round == round && !game.Finished {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass in round %d: %v", round, errs)
+		}
+	}
+}
+
+// TestEndConditions checks to make sure the game ends at the end of the round each of the end
+// conditions is met, and not before.
+func TestEndConditions(t *testing.T) {
+	playerNames := []string{"1st", "2nd", "3rd"}
+	tests := []struct {
+		name       string
+		conditions EndConditions
+		setup      func(game *Game)
+		rounds     int
+	}{
+		{"max rounds", EndConditions{MaxRounds: 3}, func(game *Game) {}, 3},
+		{"last train", DefaultEndConditions, func(game *Game) {
+			game.TrainsBought = boardInfo.TotalTrains
+		}, 1},
+		{"top stock price", DefaultEndConditions, func(game *Game) {
+			game.Companies["Erie"].StockPrice = boardInfo.MaxStockPrice()
+		}, 1},
+		{"disabled", EndConditions{}, func(game *Game) {
+			game.TrainsBought = boardInfo.TotalTrains
+			game.Companies["Erie"].StockPrice = boardInfo.MaxStockPrice()
+		}, 0},
+	}
+
+	for _, test := range tests {
+		game := NewGame(playerNames)
+		game.EndConditions = test.conditions
+		test.setup(game)
+
+		for round := 1; round <= 5; round += 1 {
+			passRound(t, game)
+			if shouldEnd := test.rounds > 0 && round >= test.rounds; game.Finished != shouldEnd {
+				t.Errorf("%s: game finished is %v after round %d", test.name, game.Finished, round)
+			}
+			if game.Finished {
+				break
+			}
+		}
+		if game.Finished && game.EndReason == "" {
+			t.Errorf("%s: game finished without a reason", test.name)
+		}
+	}
+}
+
+// TestStandings checks to make sure the final standings are ordered by net worth, using the
+// stock prices at the end of the game, with ties broken by cash.
+func TestStandings(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd", "4th"})
+	game.Companies["Erie"].StockPrice = 100
+	game.Companies["Wabash"].StockPrice = 50
+	game.Players["1st"].Cash, game.Players["1st"].Stocks["Erie"] = 100, 2
+	game.Players["2nd"].Cash, game.Players["2nd"].Stocks["Wabash"] = 200, 2
+	game.Players["3rd"].Cash = 300
+	game.Players["4th"].Cash, game.Players["4th"].Stocks["Erie"] = 50, 3
+
+	game.finishGame("testing")
+	expected := []Standing{
+		{Player: "4th", Rank: 1, Cash: 50, StockValue: 300, NetWorth: 350},
+		{Player: "3rd", Rank: 2, Cash: 300, StockValue: 0, NetWorth: 300},
+		{Player: "2nd", Rank: 3, Cash: 200, StockValue: 100, NetWorth: 300},
+		{Player: "1st", Rank: 4, Cash: 100, StockValue: 200, NetWorth: 300},
+	}
+	if len(game.Standings) != len(expected) {
+		t.Fatalf("game has %d standings, expected %d", len(game.Standings), len(expected))
+	}
+	for ind, standing := range game.Standings {
+		if standing != expected[ind] {
+			t.Errorf("standing %d is %+v, expected %+v", ind, standing, expected[ind])
+		}
+		if worth := game.Players[standing.Player].NetWorth; worth != standing.NetWorth {
+			t.Errorf("%s has net worth %d, standing has %d", standing.Player, worth,
+				standing.NetWorth)
+		}
+	}
+
+	// Players with the same net worth and cash share a rank.
+	game.Players["1st"].Cash, game.Players["1st"].Stocks["Erie"] = 200, 1
+	game.finishGame("testing")
+	if game.Standings[2].Rank != 3 || game.Standings[3].Rank != 3 {
+		t.Errorf("tied players have ranks %d and %d, expected 3", game.Standings[2].Rank,
+			game.Standings[3].Rank)
+	}
+}
+
+// TestFinishedGame checks to make sure no turns can be taken once the game is over.
+func TestFinishedGame(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	game.EndConditions = EndConditions{MaxRounds: 1}
+	passRound(t, game)
+	if !game.Finished {
+		t.Fatal("game isn't over after the last round")
+	} else if game.TurnManager.Current() != "" {
+		t.Errorf("it is %s's turn after the game is over", game.TurnManager.Current())
+	}
+
+	for name := range game.Players {
+		errs := game.PerformMarketTurn(name, MarketTurn{})
+		if len(errs) != 1 || errs[0] != errGameOver {
+			t.Errorf("%s's market turn after the game returned %v", name, errs)
+		}
+		if errs := game.UpdateCompanyInventory(name, CompanyInventory{}); len(errs) != 1 {
+			t.Errorf("%s's inventory update after the game returned %v", name, errs)
+		}
+		if errs := game.HandleCompanyEarnings(name, CompanyEarnings{}); len(errs) != 1 {
+			t.Errorf("%s's earnings after the game returned %v", name, errs)
+		}
+	}
+	if err := game.Undo(); err == nil {
+		t.Error("undo succeeded after the game was over")
+	}
+}
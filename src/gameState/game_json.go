@@ -0,0 +1,125 @@
+package gameState
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// gameJsonVersion is the version of the format games are written to JSON in. It has to be
+// increased whenever the format changes in a way older versions can't be read as, and
+// UnmarshalJSON has to keep reading every older version.
+const gameJsonVersion = 1
+
+// The jsonGame struct is the complete format a game is written to JSON in. The views clients are
+// sent only include the current player instead of the whole turn order, and leave out the names
+// of the companies and players since they are already the map keys. This format includes
+// everything, so a game can be restored from it exactly.
+type jsonGame struct {
+	Version int `json:"version"`
+
+	Round        int             `json:"round"`
+	Phase        phaseNum        `json:"phase"`
+	TurnManager  jsonTurnManager `json:"turn"`
+	Stage        string          `json:"stage"`
+	TrainsBought int             `json:"trains_bought"`
+	TechLevel    int             `json:"tech_level"`
+	UnminedCoal  []string        `json:"unmined_coal"`
+	OrphanStocks map[string]int  `json:"orphan_stocks"`
+	Finished     bool            `json:"finished"`
+	EndReason    string          `json:"end_reason"`
+	Standings    []Standing      `json:"standings"`
+
+	Companies map[string]*Company `json:"companies"`
+	Players   map[string]*Player  `json:"players"`
+
+	Options       GameOptions   `json:"options"`
+	EndConditions EndConditions `json:"end_conditions"`
+
+	Seed         int64    `json:"seed"`
+	Log          []Action `json:"log"`
+	StateVersion int      `json:"state_version"`
+}
+
+type jsonTurnManager struct {
+	Order     []string       `json:"order"`
+	Number    int            `json:"number"`
+	Passes    int            `json:"passes"`
+	Positions []TurnPosition `json:"positions"`
+}
+
+// MarshalJSON writes the complete game, including the version of the format, so it can be read
+// back by UnmarshalJSON.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonGame{
+		Version:      gameJsonVersion,
+		Round:        g.Round,
+		Phase:        g.Phase,
+		TurnManager:  jsonTurnManager(g.TurnManager),
+		Stage:        g.Stage,
+		TrainsBought: g.TrainsBought,
+		TechLevel:    g.TechLevel,
+		UnminedCoal:  g.UnminedCoal,
+		OrphanStocks: g.OrphanStocks,
+		Finished:     g.Finished,
+		EndReason:    g.EndReason,
+		Standings:    g.Standings,
+
+		Companies:     g.Companies,
+		Players:       g.Players,
+		Options:       g.Options,
+		EndConditions: g.EndConditions,
+		Seed:          g.Seed,
+		Log:           g.Log,
+		StateVersion:  g.Version,
+	})
+}
+
+// UnmarshalJSON restores a game written by MarshalJSON. Games written in a newer version of the
+// format than this one are rejected rather than risk losing whatever was added.
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var decoded jsonGame
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	} else if decoded.Version < 1 || decoded.Version > gameJsonVersion {
+		return fmt.Errorf("unsupported game version %d (expected 1 to %d)", decoded.Version,
+			gameJsonVersion)
+	}
+
+	for name, company := range decoded.Companies {
+		if company == nil {
+			return fmt.Errorf("company %q is empty", name)
+		}
+		company.Name = name
+	}
+	for name, player := range decoded.Players {
+		if player == nil {
+			return fmt.Errorf("player %q is empty", name)
+		}
+		player.Name = name
+	}
+
+	*g = Game{
+		GlobalState: GlobalState{
+			Round:        decoded.Round,
+			Phase:        decoded.Phase,
+			TurnManager:  TurnManager(decoded.TurnManager),
+			Stage:        decoded.Stage,
+			TrainsBought: decoded.TrainsBought,
+			TechLevel:    decoded.TechLevel,
+			UnminedCoal:  decoded.UnminedCoal,
+			OrphanStocks: decoded.OrphanStocks,
+			Finished:     decoded.Finished,
+			EndReason:    decoded.EndReason,
+			Standings:    decoded.Standings,
+		},
+		Companies:     decoded.Companies,
+		Players:       decoded.Players,
+		Options:       decoded.Options,
+		EndConditions: decoded.EndConditions,
+		Seed:          decoded.Seed,
+		Log:           decoded.Log,
+		Version:       decoded.StateVersion,
+	}
+	g.fillEmpty()
+	return nil
+}
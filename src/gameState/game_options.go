@@ -0,0 +1,119 @@
+package gameState
+
+import (
+	"fmt"
+	"sort"
+
+	"boardInfo"
+)
+
+// The GameOptions struct holds the rules that can be changed for each game. The starting cash is
+// split evenly between the players, and an empty list of companies means every company is
+// available. Restricted companies can't be started until the game reaches the unrestricted tech
+// level, so setting it to 1 makes every company available from the start.
+//
+// Each train that services any cities costs the running cost times the current tech level to
+// operate, and each mined coal is worth the coal value to a company with equipment. A company
+// entering receivership has its stock price set to the receivership price.
+//
+// The board is the name of a board registered with boardInfo, and an empty name means the standard
+// board. Every company in the game has to have a starting location on it.
+type GameOptions struct {
+	StartingCash      int      `json:"starting_cash"`
+	Companies         []string `json:"companies,omitempty"`
+	UnrestrictedLevel int      `json:"unrestricted_tech_level"`
+	CoalValue         int      `json:"coal_value"`
+	RunningCost       int      `json:"running_cost"`
+	ReceivershipPrice int      `json:"receivership_price"`
+	BusinessPhases    int      `json:"business_phases"`
+	Board             string   `json:"board,omitempty"`
+}
+
+// DefaultGameOptions are the standard rules, which are used for new games unless they are changed.
+var DefaultGameOptions = GameOptions{
+	StartingCash:      1500,
+	UnrestrictedLevel: 3,
+	CoalValue:         40,
+	RunningCost:       10,
+	ReceivershipPrice: 50,
+	BusinessPhases:    2,
+}
+
+// CompanyNames returns the names of every company that can be included in a game in alphabetical
+// order.
+func CompanyNames() []string {
+	result := make([]string, 0, len(companyInitCond))
+	for name := range companyInitCond {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Validate checks the options for any rules that couldn't be played with the number of players
+// provided.
+func (o GameOptions) Validate(players int) []error {
+	var errs []error
+	if players < 1 {
+		errs = append(errs, fmt.Errorf("Game must have at least one player"))
+	} else if o.StartingCash < players {
+		errs = append(errs, fmt.Errorf("Starting cash of $%d can't be split between %d players",
+			o.StartingCash, players))
+	}
+
+	seen := make(map[string]bool, len(o.Companies))
+	for _, name := range o.Companies {
+		if _, exists := companyInitCond[name]; !exists {
+			errs = append(errs, fmt.Errorf("No company named %q (options are %q)", name,
+				CompanyNames()))
+		} else if seen[name] {
+			errs = append(errs, fmt.Errorf("%s is included more than once", name))
+		}
+		seen[name] = true
+	}
+
+	if board := boardInfo.FindBoard(o.Board); board == nil {
+		errs = append(errs, fmt.Errorf("No board named %q (options are %q)", o.Board,
+			boardInfo.BoardNames()))
+	} else {
+		for _, name := range o.companyNames() {
+			if _, exists := companyInitCond[name]; exists && board.StartingLocation(name) == "" {
+				errs = append(errs, fmt.Errorf("%s has no starting location on the %s board",
+					name, o.boardName()))
+			}
+		}
+	}
+
+	if maxLevel := len(Company{}.Equipment); o.UnrestrictedLevel < 1 ||
+		o.UnrestrictedLevel > maxLevel {
+		errs = append(errs, fmt.Errorf("Unrestricted tech level %d must be between 1 and %d",
+			o.UnrestrictedLevel, maxLevel))
+	}
+	if o.CoalValue < 0 || o.RunningCost < 0 {
+		errs = append(errs, fmt.Errorf("Coal value and running cost can't be negative"))
+	}
+	if !boardInfo.IsStockPrice(o.ReceivershipPrice) {
+		errs = append(errs, fmt.Errorf("Receivership price %d is not a stock price",
+			o.ReceivershipPrice))
+	}
+	if o.BusinessPhases < 1 {
+		errs = append(errs, fmt.Errorf("Each round must have at least one business phase"))
+	}
+	return errs
+}
+
+// boardName returns the name of the board the game is played on.
+func (o GameOptions) boardName() string {
+	if o.Board == "" {
+		return boardInfo.StandardBoard
+	}
+	return o.Board
+}
+
+// companyNames returns the names of the companies included in the game.
+func (o GameOptions) companyNames() []string {
+	if len(o.Companies) == 0 {
+		return CompanyNames()
+	}
+	return o.Companies
+}
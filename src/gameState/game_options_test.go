@@ -0,0 +1,250 @@
+package gameState
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"boardInfo"
+)
+
+// testBoardName is the name of a board with a single row of hexes between Erie's starting city
+// and Wabash's, with coal in the hex next to Erie.
+const testBoardName = "game_options_test"
+
+func registerTestBoard(t *testing.T) {
+	if boardInfo.FindBoard(testBoardName) != nil {
+		return
+	}
+	board, err := boardInfo.LoadBoard([]byte(`{"hexes": {
+		"A1": {"build_cost": 10, "city": {"name": "West", "revenue": [10, 20, 30, 40, 50, 60],
+			"starting": "Erie"}},
+		"A3": {"build_cost": 20, "coal": true},
+		"A5": {"build_cost": 30},
+		"A7": {"build_cost": 10, "city": {"name": "East", "revenue": [60, 50, 40, 30, 20, 10],
+			"starting": "Wabash"}}
+	}}`))
+	if err != nil {
+		t.Fatalf("failed to load test board: %v", err)
+	} else if err := boardInfo.RegisterBoard(testBoardName, board); err != nil {
+		t.Fatalf("failed to register test board: %v", err)
+	}
+}
+
+// TestDefaultGameOptions checks to make sure the default options give every game the standard
+// rules.
+func TestDefaultGameOptions(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	if !reflect.DeepEqual(game.Options, DefaultGameOptions) {
+		t.Errorf("new game has options %+v, expected the defaults", game.Options)
+	}
+	if cash := game.Players["1st"].Cash; cash != 500 {
+		t.Errorf("players start with $%d, expected $500", cash)
+	}
+
+	restricted := 0
+	for _, company := range game.Companies {
+		if company.Restricted {
+			restricted += 1
+		}
+	}
+	if len(game.Companies) != 10 || restricted != 4 {
+		t.Errorf("game has %d companies with %d restricted, expected 10 with 4 restricted",
+			len(game.Companies), restricted)
+	}
+}
+
+// TestCustomGameOptions checks to make sure each of the options changes the rules it's meant to.
+func TestCustomGameOptions(t *testing.T) {
+	options := GameOptions{
+		StartingCash:      3000,
+		Companies:         []string{"Erie", "Pennsylvania"},
+		UnrestrictedLevel: 1,
+		CoalValue:         60,
+		RunningCost:       5,
+		ReceivershipPrice: 34,
+		BusinessPhases:    1,
+	}
+	game, errs := NewCustomGame([]string{"1st", "2nd"}, rand.Int63(), options)
+	if len(errs) > 0 {
+		t.Fatalf("failed to create game: %v", errs)
+	}
+
+	if cash := game.Players["1st"].Cash; cash != 1500 {
+		t.Errorf("players start with $%d, expected $1500", cash)
+	}
+	if len(game.Companies) != 2 || game.Companies["Erie"] == nil ||
+		game.Companies["Erie"].Restricted {
+		t.Fatalf("expected unrestricted Erie and Pennsylvania, got %v", game.sortedCompanyNames())
+	}
+
+	// Only the trains servicing cities cost anything to run.
+	company := game.Companies["Erie"]
+	company.Equipment[0], company.CoalMined = 2, 1
+	cities := game.Board().AllCities()[:2]
+	for count := 0; count <= len(cities); count += 1 {
+		serviced, revenue := make([]string, 0, count), 0
+		for _, city := range cities[:count] {
+			serviced = append(serviced, city.Location)
+			revenue += city.Revenue[0]
+		}
+		gross, costs := game.companyIncome(company, serviced)
+		if gross != 60+revenue || costs != 5*count {
+			t.Errorf("company with 2 trains and 1 coal servicing %d cities earns $%d and pays "+
+				"$%d, expected $%d and $%d", count, gross, costs, 60+revenue, 5*count)
+		}
+	}
+	company.Equipment[0], company.CoalMined = 0, 0
+
+	// Starting Erie without any capital makes it nonprofitable, so the president loses their
+	// only share and the company enters receivership.
+	if errs := startCompany(t, game, "Erie", 1, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start Erie: %v", errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+	if errs := game.UpdateCompanyInventory(company.President, CompanyInventory{}); errs != nil {
+		t.Fatalf("failed to update Erie's inventory: %v", errs)
+	}
+	if errs := game.HandleCompanyEarnings(company.President, CompanyEarnings{}); errs != nil {
+		t.Fatalf("failed to handle Erie's earnings: %v", errs)
+	}
+
+	if company.StockPrice != 34 {
+		t.Errorf("company in receivership has price %d, expected 34", company.StockPrice)
+	}
+	if !game.Phase.Market() || game.Round != 2 {
+		t.Errorf("round with one business phase ended in round %d phase %d", game.Round,
+			game.Phase)
+	}
+
+	// The options need to survive undoing an action, since that replays the game from the start.
+	if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+		t.Fatalf("failed to pass: %v", errs)
+	}
+	if err := game.Undo(); err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	} else if !reflect.DeepEqual(game.Options, options) || len(game.Companies) != 2 {
+		t.Errorf("undo changed the options to %+v", game.Options)
+	}
+}
+
+// TestInvalidGameOptions checks to make sure options that can't be played are rejected.
+func TestInvalidGameOptions(t *testing.T) {
+	tests := map[string]func(o *GameOptions){
+		"too little cash":        func(o *GameOptions) { o.StartingCash = 2 },
+		"unknown company":        func(o *GameOptions) { o.Companies = []string{"Reading"} },
+		"duplicate company":      func(o *GameOptions) { o.Companies = []string{"Erie", "Erie"} },
+		"no tech level":          func(o *GameOptions) { o.UnrestrictedLevel = 0 },
+		"negative coal":          func(o *GameOptions) { o.CoalValue = -40 },
+		"bad receivership":       func(o *GameOptions) { o.ReceivershipPrice = 51 },
+		"no business phases":     func(o *GameOptions) { o.BusinessPhases = 0 },
+		"unreachable tech level": func(o *GameOptions) { o.UnrestrictedLevel = 7 },
+		"unknown board":          func(o *GameOptions) { o.Board = "not a board" },
+		"no starting location":   func(o *GameOptions) { o.Board = testBoardName },
+	}
+	registerTestBoard(t)
+	for name, change := range tests {
+		options := DefaultGameOptions
+		change(&options)
+		if _, errs := NewCustomGame([]string{"1st", "2nd", "3rd"}, 1, options); len(errs) == 0 {
+			t.Errorf("game with %s was created", name)
+		}
+	}
+}
+
+// TestGameWithoutPennsylvania checks to make sure Pennsylvania's home cities are open to everyone
+// when it isn't one of the companies in the game, by playing a game where Baltimore & Ohio builds
+// into Philadelphia from Baltimore.
+func TestGameWithoutPennsylvania(t *testing.T) {
+	options := DefaultGameOptions
+	options.Companies = []string{"Baltimore & Ohio", "Erie"}
+	game, errs := NewCustomGame([]string{"1st", "2nd"}, rand.Int63(), options)
+	if len(errs) > 0 {
+		t.Fatalf("failed to create game: %v", errs)
+	}
+
+	const company = "Baltimore & Ohio"
+	if errs := startCompany(t, game, company, 2, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+
+	president := game.Companies[company].President
+	update := CompanyInventory{Track: []string{"G24"}}
+	if errs := game.UpdateCompanyInventory(president, update); len(errs) > 0 {
+		t.Fatalf("building in Philadelphia without Pennsylvania failed: %v", errs)
+	} else if !stringInSlice("G24", game.Companies[company].BuiltTrack) {
+		t.Errorf("%s built track %v, expected it to include G24", company,
+			game.Companies[company].BuiltTrack)
+	}
+	if errs := game.HandleCompanyEarnings(president, CompanyEarnings{}); len(errs) > 0 {
+		t.Errorf("failed to handle earnings after building in Philadelphia: %v", errs)
+	}
+}
+
+// TestGameOnCustomBoard checks to make sure a game chosen to be played on another board uses it
+// for everything, and that the board is still used after the game is restored.
+func TestGameOnCustomBoard(t *testing.T) {
+	registerTestBoard(t)
+	options := DefaultGameOptions
+	options.Companies = []string{"Erie", "Wabash"}
+	options.Board = testBoardName
+	options.UnrestrictedLevel = 1
+	game, errs := NewCustomGame([]string{"1st", "2nd"}, rand.Int63(), options)
+	if len(errs) > 0 {
+		t.Fatalf("failed to create game: %v", errs)
+	}
+	if game.Board() != boardInfo.FindBoard(testBoardName) {
+		t.Fatal("game isn't played on the board it was created with")
+	} else if !reflect.DeepEqual(game.UnminedCoal, []string{"A3"}) {
+		t.Errorf("game starts with coal in %q, expected only A3", game.UnminedCoal)
+	}
+
+	const company = "Erie"
+	if errs := startCompany(t, game, company, 2, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+
+	president := game.Companies[company].President
+	unconnected := CompanyInventory{Track: []string{"A5"}}
+	if errs := game.UpdateCompanyInventory(president, unconnected); len(errs) == 0 {
+		t.Error("built track that isn't connected to the company's track")
+	}
+	treasury := game.Companies[company].Treasury
+	update := CompanyInventory{Track: []string{"A3"}}
+	if errs := game.UpdateCompanyInventory(president, update); len(errs) > 0 {
+		t.Fatalf("failed to build track on the test board: %v", errs)
+	} else if spent := treasury - game.Companies[company].Treasury; spent != 20 {
+		t.Errorf("building on A3 cost $%d, expected the test board's $20", spent)
+	}
+
+	data, err := json.Marshal(game)
+	if err != nil {
+		t.Fatalf("failed to encode game: %v", err)
+	}
+	restored := new(Game)
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("failed to decode game: %v", err)
+	} else if restored.Board() != game.Board() {
+		t.Error("restored game isn't played on the same board")
+	}
+	if copied, err := game.Copy(); err != nil {
+		t.Fatalf("failed to copy game: %v", err)
+	} else if copied.Board() != game.Board() {
+		t.Error("copied game isn't played on the same board")
+	}
+}
@@ -1,6 +1,8 @@
 package gameState
 
 import (
+	"math/rand"
+
 	"boardInfo"
 )
 
@@ -22,31 +24,58 @@ var companyInitCond = map[string]struct {
 	"Wabash":                          {tech3: true, sort: "00-00-10", tracks: 8},
 }
 
+// NewGame creates a new game with a random seed.
 func NewGame(playerNames []string) *Game {
+	return NewSeededGame(playerNames, rand.Int63())
+}
+
+// NewSeededGame creates a new game using the seed for any random decisions made by the game, so
+// two games created with the same players and seed will behave identically.
+func NewSeededGame(playerNames []string, seed int64) *Game {
+	return newGame(playerNames, seed, DefaultGameOptions)
+}
+
+// NewCustomGame creates a new game the same as NewSeededGame, but played using the options
+// provided instead of the standard rules. An error is returned for each problem with the options.
+func NewCustomGame(playerNames []string, seed int64, options GameOptions) (*Game, []error) {
+	if errs := options.Validate(len(playerNames)); len(errs) > 0 {
+		return nil, errs
+	}
+	options.Companies = append([]string(nil), options.Companies...)
+	return newGame(playerNames, seed, options), nil
+}
+
+func newGame(playerNames []string, seed int64, options GameOptions) *Game {
 	result := new(Game)
+	result.Seed = seed
+	result.Options = options
+	result.EndConditions = DefaultEndConditions
+	result.board = boardInfo.FindBoard(options.Board)
 
+	companies := options.companyNames()
 	result.GlobalState.TechLevel = 1
-	result.GlobalState.UnminedCoal = boardInfo.StartingCoal()
-	result.GlobalState.OrphanStocks = make(map[string]int, len(companyInitCond))
+	result.GlobalState.UnminedCoal = result.board.StartingCoal()
+	result.GlobalState.OrphanStocks = make(map[string]int, len(companies))
 
-	result.Companies = make(map[string]*Company, len(companyInitCond))
-	for name, start := range companyInitCond {
+	result.Companies = make(map[string]*Company, len(companies))
+	for _, name := range companies {
+		start := companyInitCond[name]
 		result.Companies[name] = new(Company)
 		result.Companies[name].Name = name
 		result.Companies[name].HeldStock = 10
-		result.Companies[name].Restricted = start.tech3
+		result.Companies[name].Restricted = start.tech3 && options.UnrestrictedLevel > 1
 		result.Companies[name].PriceChange = start.sort
 		result.Companies[name].UnbuiltTrack = start.tracks
 		result.Companies[name].BuiltTrack = make([]string, 0, start.tracks)
 	}
 
-	startingCash := 1500 / len(playerNames)
+	startingCash := options.StartingCash / len(playerNames)
 	result.Players = make(map[string]*Player, len(playerNames))
 	for _, name := range playerNames {
 		result.Players[name] = new(Player)
 		result.Players[name].Name = name
 		result.Players[name].Cash = startingCash
-		result.Players[name].Stocks = make(map[string]int, len(companyInitCond))
+		result.Players[name].Stocks = make(map[string]int, len(companies))
 		result.Players[name].NetWorth = startingCash
 	}
 
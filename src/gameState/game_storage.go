@@ -0,0 +1,54 @@
+package gameState
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"boardInfo"
+)
+
+// gobGame has the same fields as Game, but lacks its methods so it can be handed to the gob
+// package without recursively calling Game's GobEncode/GobDecode.
+type gobGame Game
+
+// GobEncode allows a game to be encoded with encoding/gob so it can be stored and later restored.
+func (g *Game) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode((*gobGame)(g)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores a game encoded by GobEncode. Gob does not transmit empty maps or slices, so
+// they are recreated here since the rest of the package assumes the maps can always be written.
+func (g *Game) GobDecode(data []byte) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode((*gobGame)(g)); err != nil {
+		return err
+	}
+	g.fillEmpty()
+	return nil
+}
+
+// fillEmpty recreates any of the game's maps and slices that are missing after it was decoded,
+// since the rest of the package assumes the maps can always be written. It also looks up the
+// board again, since it isn't saved with the game.
+func (g *Game) fillEmpty() {
+	g.board = boardInfo.FindBoard(g.Options.Board)
+	if g.UnminedCoal == nil {
+		g.UnminedCoal = []string{}
+	}
+	if g.OrphanStocks == nil {
+		g.OrphanStocks = make(map[string]int, len(g.Companies))
+	}
+	for _, company := range g.Companies {
+		if company.BuiltTrack == nil {
+			company.BuiltTrack = make([]string, 0, company.UnbuiltTrack)
+		}
+	}
+	for _, player := range g.Players {
+		if player.Stocks == nil {
+			player.Stocks = make(map[string]int, len(g.Companies))
+		}
+	}
+}
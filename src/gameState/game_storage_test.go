@@ -0,0 +1,133 @@
+package gameState
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func gobCopy(t *testing.T, game *Game) *Game {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(game); err != nil {
+		t.Fatalf("failed to encode game: %v", err)
+	}
+	result := new(Game)
+	if err := gob.NewDecoder(&buf).Decode(result); err != nil {
+		t.Fatalf("failed to decode game: %v", err)
+	}
+	return result
+}
+
+// TestGameGobRoundTrip checks to make sure a game can be encoded and decoded without losing any
+// information, including the empty maps and slices gob normally leaves out.
+func TestGameGobRoundTrip(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd", "4th"})
+	if decoded := gobCopy(t, game); !reflect.DeepEqual(game, decoded) {
+		t.Errorf("new game changed after gob round trip\n\n%+v\n\n%+v", game, decoded)
+	}
+
+	companyName := randomCompany(false)
+	if errs := startCompany(t, game, companyName, 2, startingPrices[0][1]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", companyName, errs)
+	}
+	decoded := gobCopy(t, game)
+	if !reflect.DeepEqual(game, decoded) {
+		t.Errorf("game changed after gob round trip\n\n%+v\n\n%+v", game, decoded)
+	}
+
+	// Make sure the decoded game can still be played by having the next player buy stock, which
+	// writes to a map that would be nil if it wasn't restored.
+	for _, g := range []*Game{game, decoded} {
+		turn := MarketTurn{Purchase: &MarketAction{Company: companyName, Count: 1}}
+		if errs := g.PerformMarketTurn(g.TurnManager.Current(), turn); len(errs) > 0 {
+			t.Errorf("failed to buy stock: %v", errs)
+		}
+	}
+	if !reflect.DeepEqual(game, decoded) {
+		t.Errorf("games diverged after the same turn\n\n%+v\n\n%+v", game, decoded)
+	}
+}
+
+// jsonRoundTrip checks to make sure the game can be written to JSON and read back without losing
+// any information, and that writing the restored game again produces exactly the same JSON.
+func jsonRoundTrip(t *testing.T, game *Game) {
+	data, err := json.Marshal(game)
+	if err != nil {
+		t.Fatalf("failed to encode game: %v", err)
+	}
+	decoded := new(Game)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("failed to decode game: %v", err)
+	}
+	if !reflect.DeepEqual(game, decoded) {
+		t.Fatalf("game at %s changed after JSON round trip\n\n%+v\n\n%+v", game.timeString(),
+			game, decoded)
+	}
+	if again, err := json.Marshal(decoded); err != nil {
+		t.Fatalf("failed to encode decoded game: %v", err)
+	} else if !bytes.Equal(data, again) {
+		t.Fatalf("game at %s encoded differently after JSON round trip\n\n%s\n\n%s",
+			game.timeString(), data, again)
+	}
+}
+
+// TestGameJsonRoundTrip plays games until they are well into tech level 6, checking that the game
+// survives being written to JSON and read back throughout. The seeds are fixed so any failure can
+// be reproduced.
+func TestGameJsonRoundTrip(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		// Extra cash keeps the companies buying trains, since otherwise random turns can leave
+		// every company too poor to ever reach tech level 6.
+		options := DefaultGameOptions
+		options.StartingCash = 20000
+		game, errs := NewCustomGame([]string{"1st", "2nd", "3rd", "4th"}, seed, options)
+		if len(errs) > 0 {
+			t.Fatalf("failed to create game: %v", errs)
+		}
+		game.EndConditions = EndConditions{}
+		rng := rand.New(rand.NewSource(seed))
+		jsonRoundTrip(t, game)
+		for turn := 0; turn < 2000 && game.TechLevel < 6; turn += 10 {
+			playTurns(t, game, rng, 10)
+			jsonRoundTrip(t, game)
+		}
+		if game.TechLevel < 6 {
+			t.Fatalf("game with seed %d only reached tech level %d", seed, game.TechLevel)
+		}
+
+		// Keep going until the last train has been bought and the game ends, so the finished
+		// game and its standings are covered as well.
+		game.EndConditions = DefaultEndConditions
+		for turn := 0; turn < 2000 && !game.Finished; turn += 10 {
+			playTurns(t, game, rng, 10)
+			jsonRoundTrip(t, game)
+		}
+	}
+}
+
+// TestGameJsonInvalid checks to make sure games written in an unknown version of the format, or
+// with a phase that doesn't exist, are rejected.
+func TestGameJsonInvalid(t *testing.T) {
+	data, err := json.Marshal(NewGame([]string{"1st", "2nd"}))
+	if err != nil {
+		t.Fatalf("failed to encode game: %v", err)
+	}
+
+	tests := map[string]string{
+		"newer version": strings.Replace(string(data), `"version":1`, `"version":2`, 1),
+		"no version":    strings.Replace(string(data), `"version":1,`, "", 1),
+		"unknown phase": strings.Replace(string(data), `"phase":"Market"`, `"phase":"Lunch"`, 1),
+	}
+	for name, invalid := range tests {
+		if invalid == string(data) {
+			t.Fatalf("%s: failed to change the encoded game %s", name, data)
+		}
+		if err := json.Unmarshal([]byte(invalid), new(Game)); err == nil {
+			t.Errorf("%s: decoded without an error", name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package gameState
+
+import (
+	"fmt"
+	"sort"
+
+	"boardInfo"
+)
+
+// CheckInvariants looks for any state the rules should never allow, returning an error for each
+// problem found. A game that was only ever changed through its turn functions should never have
+// any problems, so this is meant for tests and debugging rather than validating turns.
+//
+// The companies and players are checked in sorted order so the same game always reports its
+// problems the same way.
+func (g *Game) CheckInvariants() []error {
+	var errs []error
+	players := sortedPlayerNames(g.Players)
+
+	for _, name := range g.sortedCompanyNames() {
+		company := g.Companies[name]
+		shares, most := company.HeldStock+g.OrphanStocks[name], 0
+		for _, playerName := range players {
+			count := g.Players[playerName].Stocks[name]
+			if count < 0 {
+				errs = append(errs, fmt.Errorf("%s has %d shares of %s", playerName, count, name))
+			}
+			shares += count
+			if count > most {
+				most = count
+			}
+		}
+		if shares != 10 {
+			errs = append(errs, fmt.Errorf("%s has %d shares in total", name, shares))
+		}
+
+		if company.President == "" && most > 0 {
+			errs = append(errs, fmt.Errorf("%s has no president but players hold stock", name))
+		} else if president := g.Players[company.President]; company.President != "" &&
+			(president == nil || president.Stocks[name] < most) {
+			errs = append(errs, fmt.Errorf("%s's president %s doesn't hold the most shares",
+				name, company.President))
+		}
+
+		if company.Treasury < 0 {
+			errs = append(errs, fmt.Errorf("%s has $%d in its treasury", name, company.Treasury))
+		}
+		for ind, count := range company.Equipment {
+			if count < 0 {
+				errs = append(errs, fmt.Errorf("%s has %d tech level %d equipment", name, count,
+					ind+1))
+			}
+		}
+		errs = append(errs, g.checkTrack(company)...)
+	}
+
+	for _, name := range players {
+		player := g.Players[name]
+		worth := player.Cash
+		for company, count := range player.Stocks {
+			worth += count * g.Companies[company].StockPrice
+		}
+		if player.Cash < 0 {
+			errs = append(errs, fmt.Errorf("%s has $%d in cash", name, player.Cash))
+		}
+		if player.NetWorth != worth {
+			errs = append(errs, fmt.Errorf("%s has a net worth of $%d, but is worth $%d", name,
+				player.NetWorth, worth))
+		}
+	}
+
+	if level := boardInfo.TechLevel(g.TrainsBought); g.TechLevel != level {
+		errs = append(errs, fmt.Errorf("tech level is %d after %d trains were bought",
+			g.TechLevel, g.TrainsBought))
+	}
+	if g.TrainsBought > boardInfo.TotalTrains {
+		errs = append(errs, fmt.Errorf("%d trains were bought, there are only %d",
+			g.TrainsBought, boardInfo.TotalTrains))
+	}
+	return errs
+}
+
+// checkTrack makes sure the company's built track is sorted without any duplicates, and that all
+// of it is connected.
+func (g *Game) checkTrack(company *Company) []error {
+	var errs []error
+	if !sort.StringsAreSorted(company.BuiltTrack) {
+		errs = append(errs, fmt.Errorf("%s's built track %v is not sorted", company.Name,
+			company.BuiltTrack))
+	}
+	for ind := 1; ind < len(company.BuiltTrack); ind += 1 {
+		if company.BuiltTrack[ind] == company.BuiltTrack[ind-1] {
+			errs = append(errs, fmt.Errorf("%s has track built on %q twice", company.Name,
+				company.BuiltTrack[ind]))
+		}
+	}
+	if len(company.BuiltTrack) > 1 &&
+		!g.board.TilesContiguous(company.BuiltTrack[:1], company.BuiltTrack[1:]) {
+		errs = append(errs, fmt.Errorf("%s's built track %v is not connected", company.Name,
+			company.BuiltTrack))
+	}
+	return errs
+}
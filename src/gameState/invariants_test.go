@@ -0,0 +1,89 @@
+package gameState
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestInvariantsRandomGame plays a game using random legal moves and checks the invariants after
+// every action.
+func TestInvariantsRandomGame(t *testing.T) {
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	game := NewGame([]string{"1st", "2nd", "3rd", "4th"})
+	if errs := game.CheckInvariants(); len(errs) > 0 {
+		t.Fatalf("new game breaks invariants: %v", errs)
+	}
+
+	for turn := 0; turn < 300 && !game.Finished; turn += 1 {
+		moves, err := game.LegalMoves()
+		if err != nil {
+			t.Fatalf("failed to get legal moves: %v", err)
+		}
+		randomMove(t, rng, game, moves)
+		if errs := game.CheckInvariants(); len(errs) > 0 {
+			t.Fatalf("action %+v broke invariants: %v", game.Log[len(game.Log)-1], errs)
+		}
+	}
+}
+
+// TestInvariantsReceivership checks to make sure the net worth of a president who loses their last
+// share to a nonprofitable company is updated along with everyone else's.
+func TestInvariantsReceivership(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 1, startingPrices[0][2]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+
+	president := game.Companies[company].President
+	if errs := game.UpdateCompanyInventory(president, CompanyInventory{}); errs != nil {
+		t.Fatalf("failed to update %s's inventory: %v", company, errs)
+	}
+	if errs := game.HandleCompanyEarnings(president, CompanyEarnings{}); errs != nil {
+		t.Fatalf("failed to handle %s's earnings: %v", company, errs)
+	}
+
+	if game.Companies[company].President != "" {
+		t.Fatalf("%s didn't enter receivership", company)
+	} else if errs := game.CheckInvariants(); len(errs) > 0 {
+		t.Errorf("receivership broke invariants: %v", errs)
+	}
+}
+
+// TestInvariantsBroken checks to make sure breaking each of the rules is noticed.
+func TestInvariantsBroken(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 2, startingPrices[0][2]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	president := game.Companies[company].President
+
+	breaks := map[string]func(g *Game){
+		"extra shares": func(g *Game) { g.Companies[company].HeldStock += 1 },
+		"wrong president": func(g *Game) {
+			g.Companies[company].President = g.TurnManager.Current()
+		},
+		"stale net worth":  func(g *Game) { g.Players[president].NetWorth += 10 },
+		"negative cash":    func(g *Game) { g.Players[president].Cash = -10 },
+		"wrong tech level": func(g *Game) { g.TechLevel = 2 },
+		"unsorted track": func(g *Game) {
+			g.Companies[company].BuiltTrack = []string{"G24", "G22"}
+		},
+		"disconnected track": func(g *Game) {
+			g.Companies[company].BuiltTrack = append(g.Companies[company].BuiltTrack, "Z99")
+		},
+	}
+	for name, breakGame := range breaks {
+		broken := copyGame(game)
+		breakGame(broken)
+		if errs := broken.CheckInvariants(); len(errs) == 0 {
+			t.Errorf("game with %s has no problems", name)
+		}
+	}
+}
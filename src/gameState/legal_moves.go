@@ -0,0 +1,171 @@
+package gameState
+
+import "boardInfo"
+
+// The LegalMoves struct lists what the player whose turn it is can do. Only the part for the
+// current phase and stage is filled in.
+type LegalMoves struct {
+	Player    string          `json:"player"`
+	Company   string          `json:"company,omitempty"`
+	Market    *MarketMoves    `json:"market,omitempty"`
+	Inventory *InventoryMoves `json:"inventory,omitempty"`
+	Earnings  *ServicePlan    `json:"earnings,omitempty"`
+}
+
+// The StockOption struct describes a range of shares that can be bought or sold in a company at
+// one price. Companies that haven't been started have an option for each valid starting price.
+type StockOption struct {
+	Company string `json:"company"`
+	Price   int    `json:"price"`
+	Min     int    `json:"min"`
+	Max     int    `json:"max"`
+}
+
+// The MarketMoves struct lists the stock a player can trade during their market turn. The purchase
+// counts only consider the player's current cash, so selling stock first can allow buying more.
+// Passing is always allowed.
+type MarketMoves struct {
+	Purchases []StockOption `json:"purchases"`
+	Sales     []StockOption `json:"sales"`
+	Buybacks  []StockOption `json:"buybacks"`
+}
+
+// The InventoryMoves struct lists what a company can do to its inventory. Each of the options is
+// legal on its own with the company's current treasury and the current tech level, but some of
+// them can't be combined (mining coal and building track, or building track it can't afford).
+type InventoryMoves struct {
+	Track    []string `json:"build_track"`
+	MaxTrack int      `json:"max_track"`
+	Coal     []string `json:"mine_coal"`
+	MaxBuy   int      `json:"max_buy_equipment"`
+	Scrap    [6]int   `json:"scrap_equipment"`
+}
+
+// LegalMoves enumerates the legal actions for the current turn. The moves are found using the
+// same validation used when a turn is taken, so anything listed will be accepted.
+func (g *Game) LegalMoves() (*LegalMoves, error) {
+	if g.Finished {
+		return nil, errGameOver
+	}
+
+	result := new(LegalMoves)
+	if g.Phase.Market() {
+		result.Player = g.TurnManager.Current()
+		result.Market = g.marketMoves(g.Players[result.Player])
+		return result, nil
+	}
+
+	company := g.Companies[g.TurnManager.Current()]
+	result.Player = company.President
+	result.Company = company.Name
+	if g.Stage == "inventory" {
+		result.Inventory = g.inventoryMoves(company)
+	} else {
+		plan := PlanServicing(g.board, company, g.TechLevel, g.Options.RunningCost)
+		result.Earnings = &plan
+	}
+	return result, nil
+}
+
+// marketMoves finds the stock the player can buy, sell, or have their companies buy back.
+func (g *Game) marketMoves(player *Player) *MarketMoves {
+	result := &MarketMoves{
+		Purchases: make([]StockOption, 0),
+		Sales:     make([]StockOption, 0),
+		Buybacks:  make([]StockOption, 0),
+	}
+
+	for _, name := range g.sortedCompanyNames() {
+		company := g.Companies[name]
+		prices := []int{company.StockPrice}
+		if company.StockPrice == 0 {
+			prices = nil
+			if !company.Restricted {
+				options := boardInfo.StartingStockPrices(g.TechLevel)
+				prices = options[:]
+			}
+		}
+
+		available := company.HeldStock + g.OrphanStocks[name]
+		for _, price := range prices {
+			if count := minInt(available, player.Cash/price); count > 0 {
+				result.Purchases = append(result.Purchases,
+					StockOption{Company: name, Price: price, Min: 1, Max: count})
+			}
+		}
+
+		if held := player.Stocks[name]; held > 0 {
+			// The last player held stock in a company can never be sold.
+			if held+available == 10 {
+				held -= 1
+			}
+			if held > 0 {
+				result.Sales = append(result.Sales,
+					StockOption{Company: name, Price: company.StockPrice, Min: 1, Max: held})
+			}
+		}
+
+		if company.President == player.Name && g.OrphanStocks[name] > 0 {
+			count := minInt(g.OrphanStocks[name], company.Treasury/company.StockPrice)
+			if count > 0 {
+				result.Buybacks = append(result.Buybacks,
+					StockOption{Company: name, Price: company.StockPrice, Min: 1, Max: count})
+			}
+		}
+	}
+	return result
+}
+
+// inventoryMoves finds the hexes the company can build on or mine, along with how much equipment
+// it can buy or scrap.
+func (g *Game) inventoryMoves(company *Company) *InventoryMoves {
+	result := &InventoryMoves{
+		Track:    make([]string, 0),
+		MaxTrack: minInt(company.UnbuiltTrack, g.TechLevel),
+		Coal:     make([]string, 0),
+		Scrap:    company.Equipment,
+	}
+
+	for _, coord := range g.board.AllHexes() {
+		// Most of the map can be ruled out cheaply, which leaves the full validation for the few
+		// hexes next to the company's track.
+		if stringInSlice(coord, company.BuiltTrack) ||
+			!g.board.TilesContiguous(company.BuiltTrack, []string{coord}) {
+			continue
+		}
+		update := CompanyInventory{Track: []string{coord}}
+		var errs []error
+		errs = append(errs, g.validateBusinessExpense(company, update)...)
+		errs = append(errs, g.validateBuildLimits(company, update)...)
+		errs = append(errs, g.validateCityRestrictions(company, update)...)
+		if len(errs) == 0 {
+			result.Track = append(result.Track, coord)
+		}
+	}
+	if len(result.Track) == 0 {
+		result.MaxTrack = 0
+	}
+
+	for _, coord := range g.UnminedCoal {
+		if len(g.validateBuildLimits(company, CompanyInventory{Coal: coord})) == 0 {
+			result.Coal = append(result.Coal, coord)
+		}
+	}
+
+	cost := 0
+	for ind := g.TrainsBought + 1; ind <= boardInfo.TotalTrains; ind += 1 {
+		cost += boardInfo.TrainCost(ind)
+		if cost > company.Treasury {
+			break
+		}
+		result.MaxBuy += 1
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,154 @@
+package gameState
+
+import (
+	"math/rand"
+	"testing"
+
+	"boardInfo"
+)
+
+// checkMarketMoves makes sure every listed option is accepted at both ends of its range, and that
+// going past the end of the range is rejected.
+func checkMarketMoves(t *testing.T, game *Game, player string, moves *MarketMoves) {
+	check := func(kind string, option StockOption, build func(MarketAction) MarketTurn) {
+		for _, count := range []int{option.Min, option.Max} {
+			action := MarketAction{Company: option.Company, Count: count, Price: option.Price}
+			if _, errs := game.ValidateMarketTurn(player, build(action)); len(errs) > 0 {
+				t.Errorf("legal %s %+v rejected: %v", kind, action, errs)
+			}
+		}
+		action := MarketAction{Company: option.Company, Count: option.Max + 1,
+			Price: option.Price}
+		if _, errs := game.ValidateMarketTurn(player, build(action)); len(errs) == 0 {
+			t.Errorf("%s %+v past the legal range was accepted", kind, action)
+		}
+	}
+
+	for _, option := range moves.Purchases {
+		check("purchase", option, func(a MarketAction) MarketTurn {
+			return MarketTurn{Purchase: &a}
+		})
+	}
+	for _, option := range moves.Sales {
+		check("sale", option, func(a MarketAction) MarketTurn {
+			return MarketTurn{Sales: []MarketAction{a}}
+		})
+	}
+	for _, option := range moves.Buybacks {
+		check("buyback", option, func(a MarketAction) MarketTurn {
+			return MarketTurn{Buyback: &a}
+		})
+	}
+}
+
+// checkInventoryMoves makes sure exactly the listed hexes can be built on or mined, and that the
+// company can buy as much equipment as listed but no more.
+func checkInventoryMoves(t *testing.T, game *Game, player string, moves *InventoryMoves) {
+	for _, coord := range boardInfo.DefaultBoard.AllHexes() {
+		update := CompanyInventory{Track: []string{coord}}
+		_, errs := game.ValidateCompanyInventory(player, update)
+		if legal := stringInSlice(coord, moves.Track); legal && len(errs) > 0 {
+			t.Errorf("building legal track on %q rejected: %v", coord, errs)
+		} else if !legal && len(errs) == 0 {
+			t.Errorf("building track on %q accepted but not listed", coord)
+		}
+	}
+	for _, coord := range boardInfo.DefaultBoard.StartingCoal() {
+		_, errs := game.ValidateCompanyInventory(player, CompanyInventory{Coal: coord})
+		if legal := stringInSlice(coord, moves.Coal); legal && len(errs) > 0 {
+			t.Errorf("mining legal coal on %q rejected: %v", coord, errs)
+		} else if !legal && len(errs) == 0 {
+			t.Errorf("mining coal on %q accepted but not listed", coord)
+		}
+	}
+
+	update := CompanyInventory{Buy: moves.MaxBuy}
+	if _, errs := game.ValidateCompanyInventory(player, update); errs != nil {
+		t.Errorf("buying %d equipment rejected: %v", moves.MaxBuy, errs)
+	}
+	update = CompanyInventory{Buy: moves.MaxBuy + 1}
+	if _, errs := game.ValidateCompanyInventory(player, update); errs == nil {
+		t.Errorf("buying %d equipment accepted, only %d listed", update.Buy, moves.MaxBuy)
+	}
+	update = CompanyInventory{Scrap: moves.Scrap}
+	if _, errs := game.ValidateCompanyInventory(player, update); errs != nil {
+		t.Errorf("scrapping %v rejected: %v", moves.Scrap, errs)
+	}
+}
+
+// randomMove picks one of the legal moves at random and performs it.
+func randomMove(t *testing.T, rng *rand.Rand, game *Game, moves *LegalMoves) {
+	var errs []error
+	switch {
+	case moves.Market != nil:
+		var turn MarketTurn
+		options := append(append([]StockOption(nil), moves.Market.Purchases...),
+			moves.Market.Sales...)
+		if ind := rng.Intn(len(options) + 2); ind < len(options) {
+			option := options[ind]
+			action := MarketAction{
+				Company: option.Company,
+				Count:   option.Min + rng.Intn(option.Max-option.Min+1),
+				Price:   option.Price,
+			}
+			if ind < len(moves.Market.Purchases) {
+				turn.Purchase = &action
+			} else {
+				turn.Sales = []MarketAction{action}
+			}
+		}
+		errs = game.PerformMarketTurn(moves.Player, turn)
+	case moves.Inventory != nil:
+		var update CompanyInventory
+		if len(moves.Inventory.Track) > 0 && rng.Intn(2) == 0 {
+			track := moves.Inventory.Track
+			update.Track = []string{track[rng.Intn(len(track))]}
+		} else if len(moves.Inventory.Coal) > 0 {
+			update.Coal = moves.Inventory.Coal[0]
+		} else {
+			update.Buy = rng.Intn(moves.Inventory.MaxBuy + 1)
+		}
+		errs = game.UpdateCompanyInventory(moves.Player, update)
+	default:
+		earnings := CompanyEarnings{Dividends: rng.Intn(2) == 0}
+		errs = game.HandleCompanyEarnings(moves.Player, earnings)
+	}
+	if len(errs) > 0 {
+		t.Fatalf("random legal move for %+v failed: %v", moves, errs)
+	}
+}
+
+// TestLegalMoves plays a game using random legal moves, checking at every turn that the listed
+// moves are exactly the ones the game accepts.
+func TestLegalMoves(t *testing.T) {
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	for turn := 0; turn < 150 && !game.Finished; turn += 1 {
+		moves, err := game.LegalMoves()
+		if err != nil {
+			t.Fatalf("failed to get legal moves: %v", err)
+		}
+
+		switch {
+		case moves.Market != nil:
+			if moves.Player != game.TurnManager.Current() {
+				t.Errorf("market moves are for %s, it's %s's turn", moves.Player,
+					game.TurnManager.Current())
+			}
+			checkMarketMoves(t, game, moves.Player, moves.Market)
+		case moves.Inventory != nil:
+			checkInventoryMoves(t, game, moves.Player, moves.Inventory)
+		case moves.Earnings == nil:
+			t.Fatalf("legal moves %+v are empty", moves)
+		}
+		if t.Failed() {
+			t.FailNow()
+		}
+		randomMove(t, rng, game, moves)
+	}
+
+	game.Finished = true
+	if _, err := game.LegalMoves(); err == nil {
+		t.Error("finished game has legal moves")
+	}
+}
@@ -1,25 +1,47 @@
 package gameState
 
-import (
-	"fmt"
-
-	"boardInfo"
-)
+import "boardInfo"
 
 func (g *Game) PerformMarketTurn(playerName string, turn MarketTurn) []error {
+	if g.Finished {
+		return []error{errGameOver}
+	}
 	if !g.Phase.Market() {
-		return []error{fmt.Errorf("Must be in market phase to perform market actions")}
+		return []error{ruleError(RuleError{Code: CodeWrongPhase},
+			"Must be in market phase to perform market actions")}
 	}
 
 	player := g.Players[playerName]
 	if player == nil {
-		return []error{fmt.Errorf("No player with name %q", playerName)}
+		return []error{ruleError(RuleError{Code: CodeUnknownPlayer, Player: playerName},
+			"No player with name %q", playerName)}
 	} else if expected := g.TurnManager.Current(); playerName != expected {
-		return []error{fmt.Errorf("It is currently player %s's turn", expected)}
+		return []error{ruleError(RuleError{Code: CodeNotYourTurn, Player: playerName},
+			"It is currently player %s's turn", expected)}
+	}
+
+	// Copy the actions so filling in the prices during validation doesn't modify the caller's
+	// turn, and so the turn we log can't be changed by the caller afterwards.
+	turn.Sales = append([]MarketAction(nil), turn.Sales...)
+	if turn.Purchase != nil {
+		purchase := *turn.Purchase
+		turn.Purchase = &purchase
+	}
+	if turn.Buyback != nil {
+		buyback := *turn.Buyback
+		turn.Buyback = &buyback
 	}
 
 	saleCash := 0
 	var errs []error
+	if turn.Buyback != nil {
+		if len(turn.Sales) > 0 || turn.Purchase != nil {
+			errs = append(errs, ruleError(RuleError{Code: CodeConflictingActions},
+				"Cannot buy back stock on the same turn as buying or selling stock"))
+		} else if err := g.validateStockBuyback(player, turn.Buyback); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	for ind := range turn.Sales {
 		if err := g.validateStockSale(player, &turn.Sales[ind]); err != nil {
 			errs = append(errs, err)
@@ -36,14 +58,18 @@ func (g *Game) PerformMarketTurn(playerName string, turn MarketTurn) []error {
 		return errs
 	}
 
+	g.recordAction(Action{Time: g.timeString(), Player: playerName, Market: &turn})
 	for _, saleInfo := range turn.Sales {
 		g.sellStock(player, saleInfo)
 	}
 	if turn.Purchase != nil {
 		g.buyStock(player, *turn.Purchase)
 	}
+	if turn.Buyback != nil {
+		g.buybackStock(*turn.Buyback)
+	}
 
-	g.endMarketTurn(len(turn.Sales) == 0 && turn.Purchase == nil)
+	g.endMarketTurn(len(turn.Sales) == 0 && turn.Purchase == nil && turn.Buyback == nil)
 	return nil
 }
 
@@ -51,20 +77,25 @@ func (g *Game) PerformMarketTurn(playerName string, turn MarketTurn) []error {
 // in the price if the user did not provide it and the company has already been started.
 func (g *Game) validateMarketAction(action *MarketAction) (*Company, error) {
 	if action.Company == "" {
-		return nil, fmt.Errorf("Must specify company name to buy/sell stocks")
+		return nil, ruleError(RuleError{Code: CodeUnknownCompany},
+			"Must specify company name to buy/sell stocks")
 	}
 
 	company := g.Companies[action.Company]
 	if company == nil {
-		return nil, fmt.Errorf("No company with name %q", action.Company)
+		return nil, ruleError(RuleError{Code: CodeUnknownCompany, Company: action.Company},
+			"No company with name %q", action.Company)
 	} else if action.Count == 0 {
-		return nil, fmt.Errorf("Cannot buy/sell 0 stocks in %s", company.Name)
+		return nil, ruleError(RuleError{Code: CodeInvalidCount, Company: company.Name},
+			"Cannot buy/sell 0 stocks in %s", company.Name)
 	} else if company.StockPrice != 0 {
 		// This isn't really a critical error, since we could just ignore the provided price, but
 		// since the user doesn't have to specify the price this is a simple way to make sure that
 		// if the user claims to know the price they are in fact correct.
 		if action.Price != 0 && action.Price != company.StockPrice {
-			return nil, fmt.Errorf("%s shares cannot be bought/sold for $%d (price is $%d)",
+			details := RuleError{Code: CodeWrongPrice, Company: company.Name, Amount: action.Price,
+				Limit: company.StockPrice}
+			return nil, ruleError(details, "%s shares cannot be bought/sold for $%d (price is $%d)",
 				company.Name, action.Price, company.StockPrice)
 		}
 		action.Price = company.StockPrice
@@ -89,7 +120,8 @@ func (g *Game) validateStockBuy(player *Player, buyInfo *MarketAction, saleCash
 	// started, and that the price the player wants to start it at is valid for this tech level.
 	if company.StockPrice == 0 {
 		if company.Restricted {
-			return fmt.Errorf("%s locked until tech level 3", company.Name)
+			return ruleError(RuleError{Code: CodeCompanyRestricted, Company: company.Name},
+				"%s locked until tech level %d", company.Name, g.Options.UnrestrictedLevel)
 		}
 
 		validPrice := false
@@ -97,7 +129,9 @@ func (g *Game) validateStockBuy(player *Player, buyInfo *MarketAction, saleCash
 			validPrice = validPrice || buyInfo.Price == option
 		}
 		if !validPrice {
-			return fmt.Errorf("$%d not one of the valid starting prices for tech level %d",
+			details := RuleError{Code: CodeInvalidStartingPrice, Company: company.Name,
+				Amount: buyInfo.Price}
+			return ruleError(details, "$%d not one of the valid starting prices for tech level %d",
 				buyInfo.Price, g.TechLevel)
 		}
 	}
@@ -105,10 +139,14 @@ func (g *Game) validateStockBuy(player *Player, buyInfo *MarketAction, saleCash
 	// Next check to make sure the player can afford the stock they want, and that there is enough
 	// non-player held stock available for them to purchase.
 	if buyInfo.Count*buyInfo.Price > player.Cash+saleCash {
-		return fmt.Errorf("%s has insufficient cash for %d shares of %s at $%d",
+		details := RuleError{Code: CodeInsufficientCash, Player: player.Name, Company: company.Name,
+			Amount: buyInfo.Count * buyInfo.Price, Limit: player.Cash + saleCash}
+		return ruleError(details, "%s has insufficient cash for %d shares of %s at $%d",
 			player.Name, buyInfo.Count, company.Name, buyInfo.Price)
 	} else if left := company.HeldStock + g.OrphanStocks[company.Name]; buyInfo.Count > left {
-		return fmt.Errorf("%s only has %d shares remaining", company.Name, left)
+		details := RuleError{Code: CodeInsufficientShares, Company: company.Name,
+			Amount: buyInfo.Count, Limit: left}
+		return ruleError(details, "%s only has %d shares remaining", company.Name, left)
 	}
 	return nil
 }
@@ -125,7 +163,7 @@ func (g *Game) buyStock(player *Player, buyInfo MarketAction) error {
 	if company.StockPrice == 0 {
 		company.StockPrice = buyInfo.Price
 		company.PriceChange = g.timeString()
-		company.BuiltTrack = []string{boardInfo.StartingLocation(company.Name)}
+		company.BuiltTrack = []string{g.board.StartingLocation(company.Name)}
 	}
 
 	// We have separate variables for these instead of just using the MarketAction so we can
@@ -162,14 +200,19 @@ func (g *Game) validateStockSale(player *Player, saleInfo *MarketAction) error {
 	if company, err := g.validateMarketAction(saleInfo); err != nil {
 		return err
 	} else if held := player.Stocks[company.Name]; held == 0 {
-		return fmt.Errorf("%s has no stock in %s", player.Name, company.Name)
+		details := RuleError{Code: CodeInsufficientShares, Player: player.Name,
+			Company: company.Name, Amount: saleInfo.Count}
+		return ruleError(details, "%s has no stock in %s", player.Name, company.Name)
 	} else if saleInfo.Count > held {
-		return fmt.Errorf("%s only has %d shares in %s", player.Name, held, company.Name)
+		details := RuleError{Code: CodeInsufficientShares, Player: player.Name,
+			Company: company.Name, Amount: saleInfo.Count, Limit: held}
+		return ruleError(details, "%s only has %d shares in %s", player.Name, held, company.Name)
 	} else if saleInfo.Count == held {
 		// If the sum of this player's shares, the company's shares, and the orphaned shares
 		// equal the total number of shares then no other player has any stock in this company
 		if company.HeldStock+g.OrphanStocks[company.Name]+saleInfo.Count == 10 {
-			return fmt.Errorf("Cannot sell the last player held stock in %s", company.Name)
+			return ruleError(RuleError{Code: CodeLastShares, Company: company.Name},
+				"Cannot sell the last player held stock in %s", company.Name)
 		}
 	}
 
@@ -193,7 +236,8 @@ func (g *Game) sellStock(player *Player, saleInfo MarketAction) error {
 
 	if company.President == player.Name {
 		president := player
-		for otherName, otherPlayer := range g.Players {
+		for _, otherName := range sortedPlayerNames(g.Players) {
+			otherPlayer := g.Players[otherName]
 			if otherPlayer.Stocks[company.Name] > president.Stocks[company.Name] {
 				company.President = otherName
 				president = otherPlayer
@@ -202,3 +246,47 @@ func (g *Game) sellStock(player *Player, saleInfo MarketAction) error {
 	}
 	return nil
 }
+
+// validateStockBuyback checks to make sure the player can have a company buy back its orphaned
+// stock from the bank. Only the president can make the decision, and the company needs to have
+// enough money in its treasury to pay for all of the stock.
+func (g *Game) validateStockBuyback(player *Player, buybackInfo *MarketAction) error {
+	company, err := g.validateMarketAction(buybackInfo)
+	if err != nil {
+		return err
+	}
+
+	if buybackInfo.Count < 0 {
+		return ruleError(RuleError{Code: CodeInvalidCount, Company: company.Name},
+			"Cannot buy back a negative number of shares in %s", company.Name)
+	} else if company.President != player.Name {
+		details := RuleError{Code: CodeNotPresident, Player: player.Name, Company: company.Name}
+		return ruleError(details, "%s must be the president of %s to buy back its stock",
+			player.Name, company.Name)
+	} else if orphaned := g.OrphanStocks[company.Name]; buybackInfo.Count > orphaned {
+		details := RuleError{Code: CodeInsufficientShares, Company: company.Name,
+			Amount: buybackInfo.Count, Limit: orphaned}
+		return ruleError(details, "%s only has %d orphaned shares", company.Name, orphaned)
+	} else if cost := buybackInfo.Count * buybackInfo.Price; cost > company.Treasury {
+		details := RuleError{Code: CodeInsufficientTreasury, Company: company.Name, Amount: cost,
+			Limit: company.Treasury}
+		return ruleError(details, "%s has insufficient treasury for %d shares at $%d",
+			company.Name, buybackInfo.Count, buybackInfo.Price)
+	}
+	return nil
+}
+
+// buybackStock uses a company's treasury to buy orphaned stock from the bank. The stock is then
+// held by the company, where it can be bought by players like any other stock the company holds.
+func (g *Game) buybackStock(buybackInfo MarketAction) error {
+	// This function should only be called with valid MarketActions, so we shouldn't need to do
+	// any error checking.
+	company := g.Companies[buybackInfo.Company]
+
+	company.Treasury -= buybackInfo.Count * company.StockPrice
+	company.HeldStock += buybackInfo.Count
+	if g.OrphanStocks[company.Name] -= buybackInfo.Count; g.OrphanStocks[company.Name] == 0 {
+		delete(g.OrphanStocks, company.Name)
+	}
+	return nil
+}
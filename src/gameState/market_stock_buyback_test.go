@@ -0,0 +1,135 @@
+package gameState
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func buybackStock(t *testing.T, game *Game, playerName, companyName string, count int) []error {
+	startPrice := game.Companies[companyName].StockPrice
+	startHeld := game.Companies[companyName].HeldStock
+	startTreasure := game.Companies[companyName].Treasury
+	startOrphan := game.OrphanStocks[companyName]
+
+	startStock := game.Players[playerName].Stocks[companyName]
+	startCash := game.Players[playerName].Cash
+	totalCost := count * startPrice
+
+	action := MarketAction{Company: companyName, Count: count}
+	if errs := testMarketTurn(t, game, playerName, MarketTurn{Buyback: &action}); len(errs) > 0 {
+		return errs
+	}
+
+	if stockPrice := game.Companies[companyName].StockPrice; stockPrice != startPrice {
+		t.Errorf("buying back stock changed price from $%d to $%d", startPrice, stockPrice)
+	}
+	if change := startOrphan - game.OrphanStocks[companyName]; change != count {
+		t.Errorf("bank lost %d orphaned stock after company bought back %d", change, count)
+	}
+	if _, present := game.OrphanStocks[companyName]; present && startOrphan == count {
+		t.Error("buying back all orphaned stock did not remove company name from the map")
+	}
+	if change := game.Companies[companyName].HeldStock - startHeld; change != count {
+		t.Errorf("company gained %d stock after buying back %d", change, count)
+	}
+	if change := startTreasure - game.Companies[companyName].Treasury; change != totalCost {
+		t.Errorf("company lost $%d after buying back %d stock at $%d", change, count, startPrice)
+	}
+	if stock := game.Players[playerName].Stocks[companyName]; stock != startStock {
+		t.Errorf("player stock changed from %d to %d after company buyback", startStock, stock)
+	}
+	if cash := game.Players[playerName].Cash; cash != startCash {
+		t.Errorf("player cash changed from $%d to $%d after company buyback", startCash, cash)
+	}
+	return nil
+}
+
+// TestStockBuyback checks to make sure a company cannot buy back more orphaned stock than the bank
+// has or than its treasury can afford, and that the stock and money changes appropriately when the
+// buyback is valid.
+func TestStockBuyback(t *testing.T) {
+	stockPrice := startingPrices[0][1]
+	companyName := randomCompany()
+	testBuyback := func(count, orphaned, treasury int) []error {
+		game := NewGame([]string{"player"})
+		game.Companies[companyName].StockPrice = stockPrice
+		game.Companies[companyName].President = "player"
+		game.Companies[companyName].HeldStock = 2
+		game.Companies[companyName].Treasury = treasury
+		game.OrphanStocks[companyName] = orphaned
+
+		game.Players["player"].Stocks[companyName] = 8 - orphaned
+		return buybackStock(t, game, "player", companyName, count)
+	}
+
+	if errs := testBuyback(4, 3, 4*stockPrice); len(errs) == 0 {
+		t.Error("attempt to buy back more stock than the bank held succeeded")
+	}
+	if errs := testBuyback(3, 4, 3*stockPrice-1); len(errs) == 0 {
+		t.Error("attempt to buy back more stock than the treasury can afford succeeded")
+	}
+	if errs := testBuyback(-1, 4, 0); len(errs) == 0 {
+		t.Error("attempt to buy back a negative amount of stock succeeded")
+	}
+	if errs := testBuyback(3, 4, 3*stockPrice); len(errs) > 0 {
+		t.Errorf("attempt to buy back some orphaned stock failed unexpectedly: %v", errs)
+	}
+	orphaned := rand.Intn(5) + 1
+	if errs := testBuyback(orphaned, orphaned, 500); len(errs) > 0 {
+		t.Errorf("attempt to buy back all orphaned stock failed unexpectedly: %v", errs)
+	}
+}
+
+// TestStockBuybackPresident checks to make sure only the president of a company can use its
+// treasury to buy back stock.
+func TestStockBuybackPresident(t *testing.T) {
+	game := NewGame([]string{"pres", "other"})
+	companyName := randomCompany(false)
+	game.TurnManager.Order = []string{"pres"}
+	if errs := startCompany(t, game, companyName, 5, startingPrices[0][1]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", companyName, errs)
+	}
+	game.OrphanStocks[companyName] = 2
+	game.Companies[companyName].HeldStock -= 2
+
+	game.TurnManager.Order = []string{"other"}
+	if errs := buybackStock(t, game, "other", companyName, 1); len(errs) == 0 {
+		t.Error("player who isn't the president bought back stock")
+	}
+	game.TurnManager.Order = []string{"pres"}
+	if errs := buybackStock(t, game, "pres", companyName, 2); len(errs) > 0 {
+		t.Errorf("president failed to buy back stock: %v", errs)
+	}
+}
+
+// TestStockBuybackWholeTurn checks to make sure buying back stock takes the player's whole turn,
+// so it can't be combined with buying or selling stock, and that it doesn't count as a pass.
+func TestStockBuybackWholeTurn(t *testing.T) {
+	game := NewGame([]string{"player", "other"})
+	companyName := randomCompany()
+	game.Companies[companyName].StockPrice = startingPrices[0][1]
+	game.Companies[companyName].President = "player"
+	game.Companies[companyName].Treasury = 1000
+	game.Companies[companyName].HeldStock = 2
+	game.OrphanStocks[companyName] = 4
+	game.Players["player"].Stocks[companyName] = 4
+	game.TurnManager.Order = []string{"player", "other"}
+
+	buyback := MarketAction{Company: companyName, Count: 1}
+	purchase := MarketAction{Company: companyName, Count: 1}
+	turn := MarketTurn{Buyback: &buyback, Purchase: &purchase}
+	if errs := testMarketTurn(t, game, "player", turn); len(errs) == 0 {
+		t.Error("buyback combined with a purchase succeeded")
+	}
+	turn = MarketTurn{Buyback: &buyback, Sales: []MarketAction{{Company: companyName, Count: 1}}}
+	if errs := testMarketTurn(t, game, "player", turn); len(errs) == 0 {
+		t.Error("buyback combined with a sale succeeded")
+	}
+
+	game.TurnManager.Passes = 1
+	if errs := buybackStock(t, game, "player", companyName, 1); len(errs) > 0 {
+		t.Fatalf("buyback failed unexpectedly: %v", errs)
+	} else if game.TurnManager.Passes != 0 {
+		t.Errorf("buyback counted as a pass, passes are at %d", game.TurnManager.Passes)
+	}
+}
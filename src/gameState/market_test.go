@@ -5,8 +5,6 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
-
-	"util"
 )
 
 func randomCompany(techLvl3 ...bool) string {
@@ -25,12 +23,7 @@ func randomCompany(techLvl3 ...bool) string {
 }
 
 func testMarketTurn(t *testing.T, game *Game, playerName string, turn MarketTurn) []error {
-	var backup *Game
-	if iface, err := util.Copy(game); err != nil {
-		panic(err)
-	} else {
-		backup = iface.(*Game)
-	}
+	backup := copyGame(game)
 	if !reflect.DeepEqual(backup, game) {
 		panic(fmt.Sprintf("fresh copy of the game is not equal\n\ncopy:%+v\n\noriginal:%+v\n",
 			backup, game))
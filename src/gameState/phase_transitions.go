@@ -1,31 +1,45 @@
 package gameState
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"sort"
+	"strconv"
+	"strings"
 
 	"boardInfo"
 )
 
 func (n phaseNum) MarshalJSON() ([]byte, error) {
-	result := ""
-	switch n {
-	case 0:
-		result = `"Market"`
-	case 1:
-		result = `"Business 1"`
-	case 2:
-		result = `"Business 2"`
+	if n == 0 {
+		return []byte(`"Market"`), nil
 	}
-	return []byte(result), nil
+	return []byte(fmt.Sprintf(`"Business %d"`, n)), nil
+}
+
+// UnmarshalJSON reads the phase back from the name MarshalJSON writes for it.
+func (n *phaseNum) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	} else if name == "Market" {
+		*n = 0
+		return nil
+	}
+	num, err := strconv.Atoi(strings.TrimPrefix(name, "Business "))
+	if err != nil || num < 1 || name != fmt.Sprintf("Business %d", num) {
+		return fmt.Errorf("invalid phase %q", name)
+	}
+	*n = phaseNum(num)
+	return nil
 }
 
 func (n phaseNum) Market() bool {
 	return n == 0
 }
 func (n phaseNum) Business() bool {
-	return n == 1 || n == 2
+	return n >= 1
 }
 
 func (t TurnManager) Current() string {
@@ -39,22 +53,40 @@ func (t TurnManager) MarshalJSON() ([]byte, error) {
 }
 
 func (g *GlobalState) timeString() string {
-	return fmt.Sprintf("%02d-%02d-%02d", g.Round, g.Phase, g.TurnManager.Number)
+	return fmt.Sprintf("%s-%02d", g.phaseString(), g.TurnManager.Number)
+}
+func (g *GlobalState) phaseString() string {
+	return fmt.Sprintf("%02d-%02d", g.Round, g.Phase)
 }
 
 func (g *Game) beginMarketPhase() {
+	// Every round but the first ends by starting the next market phase, which makes this the
+	// place to check if the game is over.
+	if g.Round > 0 {
+		if reason := g.endReason(); reason != "" {
+			g.finishGame(reason)
+			return
+		}
+	}
+
 	g.Round += 1
 	g.Phase = 0
 
 	g.TurnManager.Number = 0
 	g.TurnManager.Passes = 0
-	g.TurnManager.Order = make([]string, 0, len(g.Players))
-	for name, _ := range g.Players {
-		g.TurnManager.Order = append(g.TurnManager.Order, name)
+	// Players with the same cash and net worth are put in random order. To keep the game
+	// reproducible the order is shuffled using the game's seed before the stable sort instead
+	// of having the sorter break ties randomly.
+	names := sortedPlayerNames(g.Players)
+	shuffle := rand.New(rand.NewSource(g.Seed + int64(g.Round)))
+	g.TurnManager.Order = make([]string, len(names))
+	for ind, perm := range shuffle.Perm(len(names)) {
+		g.TurnManager.Order[ind] = names[perm]
 	}
-	sort.Sort(playerSorter{list: g.TurnManager.Order, info: g.Players})
+	sort.Stable(playerSorter{list: g.TurnManager.Order, info: g.Players})
+	g.TurnManager.Positions = playerPositions(g.TurnManager.Order, g.Players)
 
-	if g.TechLevel >= 3 {
+	if g.TechLevel >= g.Options.UnrestrictedLevel {
 		for _, company := range g.Companies {
 			company.Restricted = false
 		}
@@ -73,7 +105,14 @@ func (g *Game) beginBusinessPhase() {
 		}
 	}
 	sort.Sort(companySorter{list: g.TurnManager.Order, info: g.Companies})
+	g.TurnManager.Positions = companyPositions(g.TurnManager.Order, g.Companies)
 	g.Stage = "inventory"
+
+	// If no companies have been started there is no one to take a turn in the business phase,
+	// so we move straight on to the next market phase.
+	if len(g.TurnManager.Order) == 0 {
+		g.beginMarketPhase()
+	}
 }
 
 func (g *Game) endMarketTurn(pass bool) {
@@ -90,6 +129,7 @@ func (g *Game) endMarketTurn(pass bool) {
 			company := g.Companies[name]
 			company.StockPrice = boardInfo.PrevStockPrice(company.StockPrice)
 		}
+		g.updateNetWorth()
 		g.beginBusinessPhase()
 	}
 }
@@ -97,7 +137,7 @@ func (g *Game) endMarketTurn(pass bool) {
 func (g *Game) endBusinessTurn() {
 	g.Stage = "inventory"
 	if g.TurnManager.Number += 1; g.TurnManager.Number == len(g.TurnManager.Order) {
-		if g.Phase == 1 {
+		if int(g.Phase) < g.Options.BusinessPhases {
 			g.beginBusinessPhase()
 		} else {
 			g.beginMarketPhase()
@@ -105,6 +145,18 @@ func (g *Game) endBusinessTurn() {
 	}
 }
 
+// sortedPlayerNames returns the names of all the players in alphabetical order. Anything that
+// iterates over the players and could be affected by the order should use this instead of ranging
+// over the map directly so that replaying a game always produces the same results.
+func sortedPlayerNames(players map[string]*Player) []string {
+	result := make([]string, 0, len(players))
+	for name := range players {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
 // The sorters implement sort.Interface and allow us to sort lists of the player and company names
 // to determine turn order for the next phase.
 type (
@@ -128,10 +180,8 @@ func (s playerSorter) Less(i, j int) bool {
 	item1, item2 := s.info[s.list[i]], s.info[s.list[j]]
 	if item1.Cash != item2.Cash {
 		return item1.Cash < item2.Cash
-	} else if item1.NetWorth != item2.NetWorth {
-		return item1.NetWorth < item2.NetWorth
 	}
-	return rand.Float32() < 0.5
+	return item1.NetWorth < item2.NetWorth
 }
 
 func (s companySorter) Len() int {
@@ -0,0 +1,84 @@
+package gameState
+
+import "fmt"
+
+// An ErrorCode identifies which rule a turn broke. The codes never change once they've been
+// added, so clients can rely on them instead of the wording of the messages.
+type ErrorCode string
+
+// The codes for turns taken at the wrong time, where the same turn might be fine later.
+const (
+	CodeGameOver    ErrorCode = "GAME_OVER"
+	CodeWrongPhase  ErrorCode = "WRONG_PHASE"
+	CodeWrongStage  ErrorCode = "WRONG_STAGE"
+	CodeNotYourTurn ErrorCode = "NOT_YOUR_TURN"
+)
+
+// The codes for turns that break the rules no matter when they're taken.
+const (
+	CodeUnknownPlayer      ErrorCode = "UNKNOWN_PLAYER"
+	CodeUnknownCompany     ErrorCode = "UNKNOWN_COMPANY"
+	CodeConflictingActions ErrorCode = "CONFLICTING_ACTIONS"
+
+	CodeInvalidCount         ErrorCode = "INVALID_COUNT"
+	CodeWrongPrice           ErrorCode = "WRONG_PRICE"
+	CodeInvalidStartingPrice ErrorCode = "INVALID_STARTING_PRICE"
+	CodeCompanyRestricted    ErrorCode = "COMPANY_RESTRICTED"
+	CodeInsufficientCash     ErrorCode = "INSUFFICIENT_CASH"
+	CodeInsufficientShares   ErrorCode = "INSUFFICIENT_SHARES"
+	CodeLastShares           ErrorCode = "LAST_SHARES"
+	CodeNotPresident         ErrorCode = "NOT_PRESIDENT"
+	CodeInsufficientTreasury ErrorCode = "INSUFFICIENT_TREASURY"
+
+	CodeInsufficientEquipment ErrorCode = "INSUFFICIENT_EQUIPMENT"
+	CodeNoTrainsLeft          ErrorCode = "NO_TRAINS_LEFT"
+	CodeInvalidHex            ErrorCode = "INVALID_HEX"
+	CodeNoCoal                ErrorCode = "NO_COAL"
+	CodeNoTrack               ErrorCode = "NO_TRACK"
+	CodeNoTrackLeft           ErrorCode = "NO_TRACK_LEFT"
+	CodeBuildLimit            ErrorCode = "BUILD_LIMIT"
+	CodeTrackAlreadyBuilt     ErrorCode = "TRACK_ALREADY_BUILT"
+	CodeTrackNotContiguous    ErrorCode = "TRACK_NOT_CONTIGUOUS"
+	CodeHomeCityRestricted    ErrorCode = "HOME_CITY_RESTRICTED"
+	CodeCityFull              ErrorCode = "CITY_FULL"
+
+	CodeServiceCapacity ErrorCode = "SERVICE_CAPACITY"
+	CodeNotACity        ErrorCode = "NOT_A_CITY"
+	CodeCityNotReached  ErrorCode = "CITY_NOT_REACHED"
+)
+
+// WrongTime checks if the code is for a turn taken at the wrong time, rather than a turn that
+// breaks the rules.
+func (c ErrorCode) WrongTime() bool {
+	switch c {
+	case CodeGameOver, CodeWrongPhase, CodeWrongStage, CodeNotYourTurn:
+		return true
+	}
+	return false
+}
+
+// The RuleError struct is the error returned for every turn that breaks the rules. Along with the
+// code it holds whichever details apply to the rule, so clients can point at the part of the turn
+// that was wrong. The amount is what the turn asked for and the limit is the most that's allowed,
+// such as the cost of a purchase and the cash available for it.
+type RuleError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+
+	Player  string `json:"player,omitempty"`
+	Company string `json:"company,omitempty"`
+	Hex     string `json:"hex,omitempty"`
+	Amount  int    `json:"amount,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+// ruleError creates an error with the code and details provided, and the message formatted the
+// same way as fmt.Errorf.
+func ruleError(details RuleError, format string, args ...interface{}) error {
+	details.Message = fmt.Sprintf(format, args...)
+	return &details
+}
@@ -0,0 +1,95 @@
+package gameState
+
+import (
+	"errors"
+	"testing"
+)
+
+// findRuleError returns the details of the first error with the code, or nil if there isn't one.
+func findRuleError(errs []error, code ErrorCode) *RuleError {
+	for _, err := range errs {
+		var ruleErr *RuleError
+		if errors.As(err, &ruleErr) && ruleErr.Code == code {
+			return ruleErr
+		}
+	}
+	return nil
+}
+
+// TestRuleErrorCodes checks to make sure turns that break the rules return errors with the code
+// for the rule and the details of what was wrong.
+func TestRuleErrorCodes(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd", "4th"})
+	current := game.TurnManager.Current()
+	other := "1st"
+	if current == other {
+		other = "2nd"
+	}
+
+	price := startingPrices[0][2]
+	purchase := MarketTurn{
+		Purchase: &MarketAction{Company: "Pennsylvania", Count: 10, Price: price},
+	}
+	errs := testMarketTurn(t, game, other, purchase)
+	if ruleErr := findRuleError(errs, CodeNotYourTurn); ruleErr == nil {
+		t.Errorf("turn out of order returned %v, expected %s", errs, CodeNotYourTurn)
+	} else if ruleErr.Player != other || !ruleErr.Code.WrongTime() {
+		t.Errorf("turn out of order returned details %+v", *ruleErr)
+	}
+
+	errs = testMarketTurn(t, game, current, purchase)
+	if ruleErr := findRuleError(errs, CodeInsufficientCash); ruleErr == nil {
+		t.Errorf("unaffordable purchase returned %v, expected %s", errs, CodeInsufficientCash)
+	} else if ruleErr.Player != current || ruleErr.Company != "Pennsylvania" ||
+		ruleErr.Amount != 10*price || ruleErr.Limit != game.Players[current].Cash {
+		t.Errorf("unaffordable purchase returned details %+v", *ruleErr)
+	} else if ruleErr.Code.WrongTime() {
+		t.Errorf("%s is treated as a turn taken at the wrong time", ruleErr.Code)
+	}
+
+	errs = game.UpdateCompanyInventory(current, CompanyInventory{})
+	if ruleErr := findRuleError(errs, CodeWrongPhase); ruleErr == nil {
+		t.Errorf("inventory update in the market phase returned %v, expected %s", errs,
+			CodeWrongPhase)
+	}
+
+	company := game.Companies["Pennsylvania"]
+	company.BuiltTrack = []string{"G24"}
+	errs = game.validateBuildLimits(company, CompanyInventory{Track: []string{"D29"}})
+	if ruleErr := findRuleError(errs, CodeTrackNotContiguous); ruleErr == nil {
+		t.Errorf("disconnected track returned %v, expected %s", errs, CodeTrackNotContiguous)
+	} else if ruleErr.Company != company.Name {
+		t.Errorf("disconnected track returned details %+v", *ruleErr)
+	}
+
+	game.Finished = true
+	errs = testMarketTurn(t, game, current, MarketTurn{})
+	if ruleErr := findRuleError(errs, CodeGameOver); ruleErr == nil {
+		t.Errorf("turn after the game ended returned %v, expected %s", errs, CodeGameOver)
+	}
+}
+
+// TestRuleErrorMessages checks to make sure every error from a rejected turn is a rule error with
+// a code, and that its message is unchanged.
+func TestRuleErrorMessages(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	turn := MarketTurn{
+		Sales:    []MarketAction{{Company: "Pennsylvania", Count: 1}},
+		Purchase: &MarketAction{Company: "No Such Railroad", Count: 1},
+	}
+	errs := testMarketTurn(t, game, game.TurnManager.Current(), turn)
+	if len(errs) != 2 {
+		t.Fatalf("turn returned %v, expected 2 errors", errs)
+	}
+	for _, err := range errs {
+		var ruleErr *RuleError
+		if !errors.As(err, &ruleErr) {
+			t.Errorf("error %q is not a rule error", err)
+		} else if ruleErr.Code == "" || ruleErr.Message != err.Error() {
+			t.Errorf("error %q has details %+v", err, *ruleErr)
+		}
+	}
+	if msg := `No company with name "No Such Railroad"`; errs[1].Error() != msg {
+		t.Errorf("error message changed to %q, expected %q", errs[1], msg)
+	}
+}
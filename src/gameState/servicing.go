@@ -0,0 +1,109 @@
+package gameState
+
+import "boardInfo"
+
+// The TrainService struct explains what a single piece of a company's equipment does during the
+// earnings stage. Equipment of each tech level can service as many cities as its tech level, and
+// only costs anything to run if it services at least one of them.
+type TrainService struct {
+	TechLevel int      `json:"tech_level"`
+	Cities    []string `json:"cities"`
+	Revenue   int      `json:"revenue"`
+	Cost      int      `json:"cost"`
+}
+
+// The ServicePlan struct describes which cities a company should service to maximize its net
+// income, along with which equipment services each of them, the running costs of the equipment
+// used, and the cities left out.
+type ServicePlan struct {
+	Trains     []TrainService `json:"trains"`
+	Unserviced []string       `json:"unserviced"`
+	Revenue    int            `json:"revenue"`
+	Costs      int            `json:"costs"`
+}
+
+// Cities returns the locations of every city serviced in the plan.
+func (p ServicePlan) Cities() []string {
+	result := make([]string, 0)
+	for _, train := range p.Trains {
+		result = append(result, train.Cities...)
+	}
+	return result
+}
+
+// runningTrains returns how many of the company's trains have to run to service the number of
+// cities. Every train costs the same to run, so the newest equipment runs first since it can
+// service the most cities.
+func runningTrains(company *Company, cities int) int {
+	trains := 0
+	for ind := len(company.Equipment) - 1; ind >= 0 && cities > 0; ind -= 1 {
+		for count := 0; count < company.Equipment[ind] && cities > 0; count += 1 {
+			trains += 1
+			cities -= ind + 1
+		}
+	}
+	return trains
+}
+
+// PlanServicing finds the cities on the board a company should service to make the most money
+// from its city revenue at the provided tech level, after paying the running cost for each train
+// that services any of them.
+//
+// A city pays the same revenue no matter which equipment services it, so for any number of
+// cities it's best to service the ones with the highest revenue using as few trains as possible.
+// The plan tries every number of cities the company can service and keeps the one with the
+// highest net revenue. Old equipment that would cost more to run than the cities it adds are
+// worth is left idle.
+func PlanServicing(board *boardInfo.Board, company *Company, techLevel,
+	runningCost int) ServicePlan {
+	cities := board.Cities(company.BuiltTrack...)
+	boardInfo.SortCities(cities, techLevel)
+	cost := runningCost * techLevel
+
+	capacity := 0
+	for ind, count := range company.Equipment {
+		capacity += (ind + 1) * count
+	}
+	serviced, best, revenue := 0, 0, 0
+	for count := 1; count <= len(cities) && count <= capacity; count += 1 {
+		revenue += cities[count-1].Revenue[techLevel-1]
+		if net := revenue - runningTrains(company, count)*cost; net > best {
+			serviced, best = count, net
+		}
+	}
+
+	result := ServicePlan{Trains: make([]TrainService, 0), Unserviced: make([]string, 0)}
+	next := 0
+	for ind := len(company.Equipment) - 1; ind >= 0; ind -= 1 {
+		for count := 0; count < company.Equipment[ind]; count += 1 {
+			train := TrainService{TechLevel: ind + 1, Cities: make([]string, 0, ind+1)}
+			for ; next < serviced && len(train.Cities) < train.TechLevel; next += 1 {
+				train.Cities = append(train.Cities, cities[next].Location)
+				train.Revenue += cities[next].Revenue[techLevel-1]
+			}
+			if len(train.Cities) > 0 {
+				train.Cost = cost
+			}
+			result.Revenue += train.Revenue
+			result.Costs += train.Cost
+			result.Trains = append(result.Trains, train)
+		}
+	}
+	for _, city := range cities[serviced:] {
+		result.Unserviced = append(result.Unserviced, city.Location)
+	}
+	return result
+}
+
+// ServicePlan returns the plan that would be used for the current company's earnings if the
+// president doesn't choose the serviced cities themselves.
+func (g *Game) ServicePlan() (ServicePlan, error) {
+	if g.Finished {
+		return ServicePlan{}, errGameOver
+	} else if !g.Phase.Business() || g.Stage != "earnings" {
+		return ServicePlan{}, ruleError(RuleError{Code: CodeWrongStage},
+			"No company is ready to handle its earnings")
+	}
+	company := g.Companies[g.TurnManager.Current()]
+	return PlanServicing(g.board, company, g.TechLevel, g.Options.RunningCost), nil
+}
@@ -0,0 +1,223 @@
+package gameState
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"boardInfo"
+)
+
+// randomServiceCompany creates a company with track in a random set of cities and random
+// equipment, along with a random tech level for it to earn revenue at.
+func randomServiceCompany(rng *rand.Rand) (*Company, int) {
+	company := &Company{Name: "Erie"}
+	allCities := boardInfo.DefaultBoard.AllCities()
+	for _, ind := range rng.Perm(len(allCities))[:rng.Intn(13)] {
+		company.BuiltTrack = append(company.BuiltTrack, allCities[ind].Location)
+	}
+	sort.Strings(company.BuiltTrack)
+	for ind := range company.Equipment {
+		if rng.Intn(3) == 0 {
+			company.Equipment[ind] = rng.Intn(3)
+		}
+	}
+	return company, 1 + rng.Intn(6)
+}
+
+// greedyNet calculates the net revenue the company would have earned with the way cities used to
+// be chosen, which was by sorting them using the revenue for the next tech level (there is none
+// at tech level 6) and only keeping as many as the company's total capacity.
+func greedyNet(company *Company, techLevel, cost int) int {
+	cities := boardInfo.DefaultBoard.Cities(company.BuiltTrack...)
+	capacity := 0
+	for ind, count := range company.Equipment {
+		capacity += (ind + 1) * count
+	}
+	if len(cities) > capacity {
+		column := techLevel
+		if column > 5 {
+			column = 5
+		}
+		sort.SliceStable(cities, func(i, j int) bool {
+			return cities[i].Revenue[column] > cities[j].Revenue[column]
+		})
+		cities = cities[:capacity]
+	}
+
+	result := -runningTrains(company, len(cities)) * cost
+	for _, city := range cities {
+		result += city.Revenue[techLevel-1]
+	}
+	return result
+}
+
+// bestNet tries every possible set of cities the company could service to find the highest net
+// revenue it could possibly earn.
+func bestNet(company *Company, techLevel, cost int) int {
+	cities := boardInfo.DefaultBoard.Cities(company.BuiltTrack...)
+	capacity := 0
+	for ind, count := range company.Equipment {
+		capacity += (ind + 1) * count
+	}
+
+	best := 0
+	for set := 0; set < 1<<uint(len(cities)); set += 1 {
+		count, revenue := 0, 0
+		for ind, city := range cities {
+			if set&(1<<uint(ind)) != 0 {
+				count += 1
+				revenue += city.Revenue[techLevel-1]
+			}
+		}
+		if net := revenue - runningTrains(company, count)*cost; count <= capacity && net > best {
+			best = net
+		}
+	}
+	return best
+}
+
+// TestPlanServicing checks the plans for lots of random companies to make sure every plan is
+// valid, earns the most net revenue possible, and never does worse than the greedy approach.
+func TestPlanServicing(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	game := NewGame([]string{"1st", "2nd"})
+	for iter := 0; iter < 500; iter += 1 {
+		company, techLevel := randomServiceCompany(rng)
+		runningCost := 10 * rng.Intn(5)
+		cost := runningCost * techLevel
+		plan := PlanServicing(boardInfo.DefaultBoard, company, techLevel, runningCost)
+
+		trains, seen, revenue, costs := 0, map[string]bool{}, 0, 0
+		for _, count := range company.Equipment {
+			trains += count
+		}
+		if len(plan.Trains) != trains {
+			t.Errorf("plan for %v has %d trains, expected %d", company.Equipment,
+				len(plan.Trains), trains)
+		}
+		for _, train := range plan.Trains {
+			if len(train.Cities) > train.TechLevel {
+				t.Errorf("tech level %d train services %d cities", train.TechLevel,
+					len(train.Cities))
+			} else if company.Equipment[train.TechLevel-1] == 0 {
+				t.Errorf("plan uses tech level %d train the company doesn't have", train.TechLevel)
+			}
+			trainRevenue := 0
+			for _, city := range boardInfo.DefaultBoard.Cities(train.Cities...) {
+				trainRevenue += city.Revenue[techLevel-1]
+			}
+			if trainRevenue != train.Revenue {
+				t.Errorf("train has revenue $%d, but its cities pay $%d", train.Revenue,
+					trainRevenue)
+			}
+			expected := 0
+			if len(train.Cities) > 0 {
+				expected = cost
+			}
+			if train.Cost != expected {
+				t.Errorf("train servicing %d cities costs $%d to run, expected $%d",
+					len(train.Cities), train.Cost, expected)
+			}
+			revenue += train.Revenue
+			costs += train.Cost
+		}
+		for _, coord := range append(plan.Cities(), plan.Unserviced...) {
+			if seen[coord] {
+				t.Errorf("plan includes %q more than once", coord)
+			}
+			seen[coord] = true
+		}
+		cities := boardInfo.DefaultBoard.Cities(company.BuiltTrack...)
+		if len(seen) != len(cities) {
+			t.Errorf("plan includes %d cities, company is in %d", len(seen), len(cities))
+		}
+
+		earnings := CompanyEarnings{Serviced: plan.Cities()}
+		if errs := game.validateServicedCities(company, earnings); len(errs) > 0 {
+			t.Errorf("plan %+v is invalid: %v", plan, errs)
+		}
+		if revenue != plan.Revenue || costs != plan.Costs {
+			t.Errorf("plan has revenue $%d and costs $%d, but its trains earn $%d and cost $%d",
+				plan.Revenue, plan.Costs, revenue, costs)
+		}
+		if expected := runningTrains(company, len(plan.Cities())) * cost; costs != expected {
+			t.Errorf("plan costs $%d, but the game would charge $%d", costs, expected)
+		}
+		net := plan.Revenue - plan.Costs
+		if greedy := greedyNet(company, techLevel, cost); net < greedy {
+			t.Errorf("plan nets $%d, greedy approach nets $%d", net, greedy)
+		}
+		if best := bestNet(company, techLevel, cost); net != best {
+			t.Errorf("plan nets $%d, best possible is $%d", net, best)
+		}
+	}
+}
+
+// TestPlanServicingIdle checks to make sure old equipment is left idle when the only city it could
+// service pays less than the train costs to run.
+func TestPlanServicingIdle(t *testing.T) {
+	board, err := boardInfo.LoadBoard([]byte(`{"hexes": {
+		"A1": {"build_cost": 10, "city": {"name": "A", "revenue": [50, 50, 50, 50, 50, 50]}},
+		"A3": {"build_cost": 10, "city": {"name": "B", "revenue": [50, 50, 50, 50, 50, 50]}},
+		"A5": {"build_cost": 10, "city": {"name": "C", "revenue": [50, 50, 50, 50, 50, 50]}},
+		"A7": {"build_cost": 10, "city": {"name": "D", "revenue": [10, 10, 10, 10, 10, 10]}}
+	}}`))
+	if err != nil {
+		t.Fatalf("failed to load board: %v", err)
+	}
+	company := &Company{Name: "Erie", BuiltTrack: []string{"A1", "A3", "A5", "A7"}}
+	company.Equipment[0], company.Equipment[2] = 1, 1
+
+	// Each train costs $30 to run at tech level 3, which is more than the last city pays.
+	plan := PlanServicing(board, company, 3, 10)
+	expected := ServicePlan{
+		Trains: []TrainService{
+			{TechLevel: 3, Cities: []string{"A1", "A3", "A5"}, Revenue: 150, Cost: 30},
+			{TechLevel: 1, Cities: []string{}, Revenue: 0, Cost: 0},
+		},
+		Unserviced: []string{"A7"},
+		Revenue:    150,
+		Costs:      30,
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("plan is %+v, expected %+v", plan, expected)
+	}
+}
+
+// startEarnings starts a company and plays until it is ready to handle its earnings, with the
+// provided inventory update for its business turn. It returns the name of the company.
+func startEarnings(t *testing.T, game *Game, update CompanyInventory) string {
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 2, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+
+	president := game.Companies[company].President
+	if errs := game.UpdateCompanyInventory(president, update); errs != nil {
+		t.Fatalf("failed to update %s's inventory: %v", company, errs)
+	}
+	return company
+}
+
+// TestServicePlanStage checks to make sure the game only has a service plan during the earnings
+// stage of a business phase.
+func TestServicePlanStage(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	if _, err := game.ServicePlan(); err == nil {
+		t.Error("game has a service plan during the market phase")
+	}
+
+	company := startEarnings(t, game, CompanyInventory{Buy: 1})
+	if plan, err := game.ServicePlan(); err != nil {
+		t.Errorf("failed to get service plan in the earnings stage: %v", err)
+	} else if len(plan.Trains) != 1 || len(plan.Cities()) != 1 {
+		t.Errorf("plan for %s's first train is %+v", company, plan)
+	}
+}
@@ -1,11 +1,16 @@
 package gameState
 
+import "boardInfo"
+
 type phaseNum int
 
 type TurnManager struct {
 	Order  []string
 	Number int
 	Passes int
+
+	// Positions records why each player or company is where it is in the order.
+	Positions []TurnPosition
 }
 
 // The GlobalState struct holds all general board game state.
@@ -19,6 +24,10 @@ type GlobalState struct {
 	TechLevel    int            `json:"tech_level"`
 	UnminedCoal  []string       `json:"unmined_coal"`
 	OrphanStocks map[string]int `json:"orphan_stocks"`
+
+	Finished  bool       `json:"finished"`
+	EndReason string     `json:"end_reason,omitempty"`
+	Standings []Standing `json:"standings,omitempty"`
 }
 
 // The Company struct holds all of the information relevant to a single company.
@@ -47,11 +56,33 @@ type Player struct {
 	Stocks   map[string]int `json:"stocks"`
 }
 
-// The Game struct holds all information for an active game.
+// The Game struct holds all information for an active game. The seed, options, end conditions and
+// the log of every accepted action are all that is needed (along with the player names) to replay
+// the game. Its JSON is a complete, versioned format the game can be restored from, while clients
+// are sent the global state, companies and players separately.
+//
+// The board isn't saved with the game, since it's looked up again from the options whenever the
+// game is created or restored.
 type Game struct {
 	GlobalState
 	Companies map[string]*Company
 	Players   map[string]*Player
+
+	Options       GameOptions
+	EndConditions EndConditions
+
+	Seed int64
+	Log  []Action
+
+	// Version increases every time the game changes, including when an action is undone.
+	Version int
+
+	board *boardInfo.Board
+}
+
+// Board returns the board the game is played on.
+func (g *Game) Board() *boardInfo.Board {
+	return g.board
 }
 
 // The MarketAction struct represents a single action that can be performed during the market
@@ -66,12 +97,13 @@ type MarketAction struct {
 // turn. The player can sell as many stocks as they have, but are only allowed to buy from one
 // company during a single turn.
 //
-// TODO: players should be able to use a company's treasury to recover orphaned stock from the
-// bank if they are the president. This action can only be done for one company per turn similar
-// to buying stock, and cannot be done on the same turn the player uses to manage their own stock.
+// Instead of managing their own stock a player can use a company's treasury to buy back orphaned
+// stock from the bank if they are the president. This can only be done for one company per turn,
+// and cannot be done on the same turn the player sells or buys stock.
 type MarketTurn struct {
 	Sales    []MarketAction `json:"sales,omitempty"`
 	Purchase *MarketAction  `json:"purchase,omitempty"`
+	Buyback  *MarketAction  `json:"buyback,omitempty"`
 }
 
 // CompanyInventory represents all the available actions available to a company that effect it's
@@ -91,3 +123,14 @@ type CompanyEarnings struct {
 	Serviced  []string `json:"serviced_cities"`
 	Dividends bool     `json:"pay_dividends"`
 }
+
+// The Action struct records a single action that was accepted by the game, along with when it
+// happened and the player who performed it. Only one of the turn fields will be set.
+type Action struct {
+	Time   string `json:"time"`
+	Player string `json:"player"`
+
+	Market    *MarketTurn       `json:"market,omitempty"`
+	Inventory *CompanyInventory `json:"inventory,omitempty"`
+	Earnings  *CompanyEarnings  `json:"earnings,omitempty"`
+}
@@ -212,6 +212,7 @@ func TestBusinessPhaseEnd(t *testing.T) {
 	// Start off with a game in the market phase so we can call beginBusinessPhase.
 	game := &Game{
 		GlobalState: GlobalState{Round: 1, Phase: 0},
+		Options:     DefaultGameOptions,
 		Companies: map[string]*Company{
 			"1st": {Name: "1st", President: "president", StockPrice: 350},
 			"2nd": {Name: "2nd", President: "president", StockPrice: 300},
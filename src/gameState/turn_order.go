@@ -0,0 +1,120 @@
+package gameState
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The TurnPosition struct explains why a player or company is where it is in the turn order.
+// Players are ordered by the least cash, then the least net worth, and companies by the highest
+// stock price, then whichever reached that price first. The values are the ones the order was
+// sorted by when the phase began, since they can change during the phase without changing the
+// order.
+type TurnPosition struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+
+	Cash     int `json:"cash,omitempty"`
+	NetWorth int `json:"net_worth,omitempty"`
+
+	StockPrice  int    `json:"stock_price,omitempty"`
+	PriceChange string `json:"price_changed,omitempty"`
+}
+
+// The TurnOrder struct is the whole turn order for the current phase. The upcoming list has
+// everyone who will act after the current player or company, in order, and in the market phase
+// the phase ends once the number of passes in a row reaches the number needed.
+type TurnOrder struct {
+	Current      string         `json:"current"`
+	Upcoming     []string       `json:"upcoming"`
+	Passes       int            `json:"passes"`
+	PassesNeeded int            `json:"passes_needed,omitempty"`
+	Order        []TurnPosition `json:"order"`
+}
+
+// TurnOrder describes the order everyone acts in during the current phase and why.
+func (g *GlobalState) TurnOrder() TurnOrder {
+	order := g.TurnManager.Order
+	result := TurnOrder{
+		Current:  g.TurnManager.Current(),
+		Upcoming: []string{},
+		Passes:   g.TurnManager.Passes,
+		Order:    g.TurnManager.Positions,
+	}
+	if len(order) == 0 || g.Finished {
+		return result
+	}
+
+	// The market phase keeps going around the players until they all pass, while each company
+	// only gets one turn in a business phase.
+	if g.Phase.Market() {
+		result.PassesNeeded = len(order)
+		for ind := 1; ind < len(order); ind += 1 {
+			result.Upcoming = append(result.Upcoming, order[(g.TurnManager.Number+ind)%len(order)])
+		}
+	} else if g.TurnManager.Number+1 < len(order) {
+		result.Upcoming = append(result.Upcoming, order[g.TurnManager.Number+1:]...)
+	}
+	return result
+}
+
+// playerPositions records why the players were sorted into the order for the market phase.
+func playerPositions(order []string, players map[string]*Player) []TurnPosition {
+	var result []TurnPosition
+	for _, name := range order {
+		player := players[name]
+		var cashTies, worthTies []string
+		for _, otherName := range order {
+			if other := players[otherName]; otherName != name && other.Cash == player.Cash {
+				cashTies = append(cashTies, otherName)
+				if other.NetWorth == player.NetWorth {
+					worthTies = append(worthTies, otherName)
+				}
+			}
+		}
+
+		reason := fmt.Sprintf("has $%d in cash", player.Cash)
+		if len(worthTies) > 0 {
+			reason = fmt.Sprintf("has $%d in cash and $%d net worth (the same as %s), so the "+
+				"order was chosen at random", player.Cash, player.NetWorth,
+				strings.Join(worthTies, ", "))
+		} else if len(cashTies) > 0 {
+			reason = fmt.Sprintf("has $%d in cash (the same as %s) and $%d net worth",
+				player.Cash, strings.Join(cashTies, ", "), player.NetWorth)
+		}
+		result = append(result, TurnPosition{
+			Name:     name,
+			Reason:   reason,
+			Cash:     player.Cash,
+			NetWorth: player.NetWorth,
+		})
+	}
+	return result
+}
+
+// companyPositions records why the companies were sorted into the order for a business phase.
+func companyPositions(order []string, companies map[string]*Company) []TurnPosition {
+	var result []TurnPosition
+	for _, name := range order {
+		company := companies[name]
+		var ties []string
+		for _, otherName := range order {
+			if otherName != name && companies[otherName].StockPrice == company.StockPrice {
+				ties = append(ties, otherName)
+			}
+		}
+
+		reason := fmt.Sprintf("stock price of $%d", company.StockPrice)
+		if len(ties) > 0 {
+			reason = fmt.Sprintf("stock price of $%d (the same as %s), reached at %s",
+				company.StockPrice, strings.Join(ties, ", "), company.PriceChange)
+		}
+		result = append(result, TurnPosition{
+			Name:        name,
+			Reason:      reason,
+			StockPrice:  company.StockPrice,
+			PriceChange: company.PriceChange,
+		})
+	}
+	return result
+}
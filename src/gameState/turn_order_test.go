@@ -0,0 +1,113 @@
+package gameState
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMarketTurnOrderReasons checks to make sure the turn order for the market phase explains how
+// the players were sorted, and keeps track of who acts next and how many players have passed.
+func TestMarketTurnOrderReasons(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	for name, cash := range map[string]int{"1st": 100, "2nd": 200, "3rd": 200} {
+		game.Players[name].Cash = cash
+	}
+	game.Players["2nd"].NetWorth = 300
+	game.Players["3rd"].NetWorth = 250
+	game.beginMarketPhase()
+
+	expected := []TurnPosition{
+		{Name: "1st", Reason: "has $100 in cash", Cash: 100, NetWorth: 500},
+		{Name: "3rd", Reason: "has $200 in cash (the same as 2nd) and $250 net worth", Cash: 200,
+			NetWorth: 250},
+		{Name: "2nd", Reason: "has $200 in cash (the same as 3rd) and $300 net worth", Cash: 200,
+			NetWorth: 300},
+	}
+	order := game.TurnOrder()
+	if !reflect.DeepEqual(order.Order, expected) {
+		t.Errorf("turn order is %+v, expected %+v", order.Order, expected)
+	}
+	if order.Current != "1st" || !reflect.DeepEqual(order.Upcoming, []string{"3rd", "2nd"}) ||
+		order.Passes != 0 || order.PassesNeeded != 3 {
+		t.Errorf("turn order at the start of the phase is %+v", order)
+	}
+
+	// The order goes back around to the first player, and the reasons don't change even though
+	// the players' cash does.
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 1, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for _, name := range []string{"3rd", "2nd"} {
+		if errs := testMarketTurn(t, game, name, MarketTurn{}); len(errs) > 0 {
+			t.Fatalf("%s failed to pass: %v", name, errs)
+		}
+	}
+	order = game.TurnOrder()
+	if order.Current != "1st" || !reflect.DeepEqual(order.Upcoming, []string{"3rd", "2nd"}) ||
+		order.Passes != 2 {
+		t.Errorf("turn order after two passes is %+v", order)
+	}
+	if !reflect.DeepEqual(order.Order, expected) {
+		t.Errorf("turn order changed to %+v during the phase", order.Order)
+	}
+}
+
+// TestMarketTurnOrderTies checks to make sure players that are tied on both cash and net worth
+// are told their order was chosen at random.
+func TestMarketTurnOrderTies(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	for _, position := range game.TurnOrder().Order {
+		other := "1st"
+		if position.Name == other {
+			other = "2nd"
+		}
+		expected := "has $750 in cash and $750 net worth (the same as " + other +
+			"), so the order was chosen at random"
+		if position.Reason != expected {
+			t.Errorf("%s is in the order because they %s, expected %s", position.Name,
+				position.Reason, expected)
+		}
+	}
+}
+
+// TestBusinessTurnOrderReasons checks to make sure the turn order for a business phase explains
+// how the companies were sorted, and lists the companies that haven't acted yet.
+func TestBusinessTurnOrderReasons(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd"})
+	prices := map[string]int{"Erie": 66, "Wabash": 66, "Pennsylvania": 74}
+	changes := map[string]string{
+		"Erie":         "01-00-03",
+		"Wabash":       "01-00-01",
+		"Pennsylvania": "01-00-05",
+	}
+	for name, price := range prices {
+		company := game.Companies[name]
+		company.President = "1st"
+		company.StockPrice = price
+		company.PriceChange = changes[name]
+	}
+	game.beginBusinessPhase()
+
+	expected := []TurnPosition{
+		{Name: "Pennsylvania", Reason: "stock price of $74", StockPrice: 74,
+			PriceChange: "01-00-05"},
+		{Name: "Wabash", Reason: "stock price of $66 (the same as Erie), reached at 01-00-01",
+			StockPrice: 66, PriceChange: "01-00-01"},
+		{Name: "Erie", Reason: "stock price of $66 (the same as Wabash), reached at 01-00-03",
+			StockPrice: 66, PriceChange: "01-00-03"},
+	}
+	order := game.TurnOrder()
+	if !reflect.DeepEqual(order.Order, expected) {
+		t.Errorf("turn order is %+v, expected %+v", order.Order, expected)
+	}
+	if order.Current != "Pennsylvania" || order.PassesNeeded != 0 ||
+		!reflect.DeepEqual(order.Upcoming, []string{"Wabash", "Erie"}) {
+		t.Errorf("turn order at the start of the phase is %+v", order)
+	}
+
+	game.TurnManager.Number = 2
+	if order := game.TurnOrder(); order.Current != "Erie" || len(order.Upcoming) != 0 {
+		t.Errorf("turn order for the last company is %+v", order)
+	}
+}
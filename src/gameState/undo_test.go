@@ -0,0 +1,88 @@
+package gameState
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"util"
+)
+
+// copyGame copies every part of the game, including the log, so tests can compare the whole game
+// before and after an action. The board is never changed, so the copy shares it.
+func copyGame(game *Game) *Game {
+	iface, err := util.Copy(game)
+	if err != nil {
+		panic(err)
+	}
+	result := iface.(*Game)
+	result.board = game.board
+	return result
+}
+
+// TestUndo checks to make sure undoing an action puts the game back in exactly the state it was
+// in before the action was taken, both with the standard rules and with options that change them.
+func TestUndo(t *testing.T) {
+	custom := DefaultGameOptions
+	custom.StartingCash = 2400
+	custom.Companies = []string{"Baltimore & Ohio", "Erie", "Pennsylvania", "Wabash"}
+	custom.CoalValue = 60
+	custom.BusinessPhases = 1
+	for _, options := range []GameOptions{DefaultGameOptions, custom} {
+		game, errs := NewCustomGame([]string{"1st", "2nd", "3rd", "4th"}, rand.Int63(), options)
+		if len(errs) > 0 {
+			t.Fatalf("failed to create game with %+v: %v", options, errs)
+		}
+		if err := game.Undo(); err == nil {
+			t.Error("undo succeeded before any actions were taken")
+		}
+		playTurns(t, game, rand.New(rand.NewSource(game.Seed)), rand.Intn(100))
+
+		// Make sure the next action isn't the last one in a phase, since it couldn't be undone.
+		for game.Phase.Market() && game.TurnManager.Passes+1 >= len(game.TurnManager.Order) {
+			playTurns(t, game, rand.New(rand.NewSource(game.Seed)), 1)
+		}
+		for game.Phase.Business() && game.Stage == "earnings" &&
+			game.TurnManager.Number+1 >= len(game.TurnManager.Order) {
+			playTurns(t, game, rand.New(rand.NewSource(game.Seed)), 1)
+		}
+
+		before := copyGame(game)
+		playTurns(t, game, rand.New(rand.NewSource(game.Seed)), 1)
+		if reflect.DeepEqual(before, game) {
+			t.Fatal("taking a turn didn't change the game")
+		}
+		if err := game.Undo(); err != nil {
+			t.Fatalf("failed to undo action at %s: %v", before.timeString(), err)
+		} else if game.Version != before.Version+2 {
+			t.Errorf("game is at version %d after an action and undo, expected %d",
+				game.Version, before.Version+2)
+		}
+		before.Version = game.Version
+		if !reflect.DeepEqual(before, game) {
+			t.Errorf("game after undo doesn't match the game before the action\n\n%+v\n\n%+v",
+				before, game)
+		}
+	}
+}
+
+// TestUndoNewPhase checks to make sure the action that ended a phase cannot be undone.
+func TestUndoNewPhase(t *testing.T) {
+	game := NewGame([]string{"1st", "2nd", "3rd"})
+	company := randomCompany(false)
+	if errs := startCompany(t, game, company, 2, startingPrices[0][0]); len(errs) > 0 {
+		t.Fatalf("failed to start %s: %v", company, errs)
+	}
+	for game.Phase.Market() {
+		if errs := game.PerformMarketTurn(game.TurnManager.Current(), MarketTurn{}); errs != nil {
+			t.Fatalf("failed to pass: %v", errs)
+		}
+	}
+
+	before := copyGame(game)
+	if err := game.Undo(); err == nil {
+		t.Error("undo succeeded after the business phase started")
+	} else if !reflect.DeepEqual(before, game) {
+		t.Error("failed undo changed the game")
+	}
+}